@@ -17,14 +17,53 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// Workload kinds supported by an Application
+const (
+	WorkloadKindDeployment  = "Deployment"
+	WorkloadKindStatefulSet = "StatefulSet"
+	WorkloadKindDaemonSet   = "DaemonSet"
+)
+
+// Adoption policies of an Application
+const (
+	AdoptionPolicyNever     = "Never"
+	AdoptionPolicyIfUnowned = "IfUnowned"
+	AdoptionPolicyForce     = "Force"
+)
+
+// Phases of an Application
+const (
+	PhasePending     = "Pending"
+	PhaseProgressing = "Progressing"
+	PhaseAvailable   = "Available"
+	PhaseDegraded    = "Degraded"
+	PhaseWaiting     = "Waiting"
+	PhaseSuspended   = "Suspended"
+)
+
+// Condition types of an Application
+const (
+	ConditionAvailable   = "Available"
+	ConditionProgressing = "Progressing"
+	ConditionDegraded    = "Degraded"
+	// ConditionTemplateRendered reports whether the template of the application rendered successfully
+	ConditionTemplateRendered = "TemplateRendered"
+)
+
 // ApplicationSpec defines the desired state of Application
+// +kubebuilder:validation:XValidation:rule="!has(self.persistence) || (has(self.workloadKind) && self.workloadKind == 'StatefulSet')",message="persistence requires workloadKind StatefulSet"
 type ApplicationSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
@@ -34,15 +73,109 @@ type ApplicationSpec struct {
 	// Image is application docker image
 	Image string `json:"image"`
 
+	// ImagePullPolicy is the pull policy of the application image
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +kubebuilder:default=IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImagePullSecrets are the Secrets of the namespace used to pull the images of the application
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// ImageUpdate makes the operator update the tag of the image to the newest
+	// tag of its repository matching the policy. The tags are listed with the
+	// credentials of the image pull secrets, from the registries the image policy
+	// of the operator allows
+	// +optional
+	ImageUpdate *ImageUpdate `json:"imageUpdate,omitempty"`
+
 	// Port is the port exposed application
 	Port int32 `json:"port"`
 
+	// Suspend scales the workload of the application to zero, suspends its CronJobs and stops
+	// correcting the drift of its resources. Resuming restores the resources from the spec,
+	// including the previous number of replicas
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
 	// Replicas refer to the desired number of identical copies (pods)
-	// of an application that should be running at any given time
+	// of an application that should be running at any given time. When unset the
+	// replicas of an existing workload are left as they are, for example to an autoscaler
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
 
-	// StartCmd is the application start command
+	// WorkloadKind is the kind of workload running the application,
+	// Deployment, StatefulSet or DaemonSet which runs one pod per node and ignores replicas
+	// +optional
+	// +kubebuilder:default=Deployment
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet
+	WorkloadKind string `json:"workloadKind,omitempty"`
+
+	// UpdateStrategy defines how the pods of a Deployment or DaemonSet are replaced
+	// +optional
+	UpdateStrategy *UpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// NodeSelector restricts the nodes the application's pods are scheduled on
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations allow the application's pods to be scheduled on tainted nodes
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the node and pod affinity of the application's pods
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints define how the application's pods are spread across topology domains
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Security defines the security context of the application's pods
+	// +optional
+	Security *Security `json:"security,omitempty"`
+
+	// ServiceAccount defines the ServiceAccount the application's pods run as,
+	// the namespace default ServiceAccount is used if unset
+	// +optional
+	ServiceAccount *ServiceAccount `json:"serviceAccount,omitempty"`
+
+	// Hooks define Jobs run around the rollout of the application
+	// +optional
+	Hooks *Hooks `json:"hooks,omitempty"`
+
+	// CronJobs are periodic tasks run with the image, env and config of the application
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	CronJobs []CronJob `json:"cronJobs,omitempty"`
+
+	// DependsOn lists the Applications which must be Available before the
+	// workload of this application is created
+	// +optional
+	// +listType=atomic
+	DependsOn []Dependency `json:"dependsOn,omitempty"`
+
+	// NetworkPolicy restricts the traffic of the application's pods to the listed
+	// sources and destinations, the pods are reachable from any pod if unset
+	// +optional
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+
+	// HighAvailability spreads the application's pods across zones and nodes.
+	// Unless topologySpreadConstraints are set, zone and hostname spread constraints are generated,
+	// and a preferred pod anti-affinity on the hostname is added to the affinity.
+	// +optional
+	HighAvailability bool `json:"highAvailability,omitempty"`
+
+	// Persistence defines the persistent volumes of a StatefulSet application
+	// +optional
+	Persistence *Persistence `json:"persistence,omitempty"`
+
+	// StartCmd is the application start command, the executable run with Args
+	// instead of the entrypoint of the image
 	// +optional
 	StartCmd string `json:"startCmd,omitempty"`
 
@@ -54,10 +187,442 @@ type ApplicationSpec struct {
 	// +optional
 	Env []corev1.EnvVar `json:"env,omitempty"`
 
+	// InitContainers run before the application container. An init container with
+	// restartPolicy Always is a native sidecar which keeps running alongside it
+	// +optional
+	InitContainers []ExtraContainer `json:"initContainers,omitempty"`
+
+	// Sidecars are containers run alongside the application container
+	// +optional
+	Sidecars []ExtraContainer `json:"sidecars,omitempty"`
+
+	// ConfigFrom is a list of ConfigMaps and Secrets whose keys are exposed
+	// to the application as environment variables
+	// +optional
+	ConfigFrom []ConfigFromSource `json:"configFrom,omitempty"`
+
+	// Volumes is a list of ConfigMaps and Secrets mounted into the application as files
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Volumes []ConfigVolume `json:"volumes,omitempty"`
+
+	// ConfigFiles maps absolute file paths in the container to their content.
+	// The files are stored in an immutable ConfigMap managed by the operator.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.all(path, path.startsWith('/'))",message="configFiles keys must be absolute paths"
+	ConfigFiles map[string]string `json:"configFiles,omitempty"`
+
+	// ConfigFilesTemplate renders the content of configFiles as Go templates
+	// with the Application as data, e.g. {{ .Spec.Port }}
+	// +optional
+	ConfigFilesTemplate bool `json:"configFilesTemplate,omitempty"`
+
+	// Template is the name of a template set used to generate the Deployment, Service and
+	// Ingress of the application, a ConfigMap labelled apps.xinyan.cn/template=true in the
+	// operator namespace. Resources without a template in the set are generated as usual.
+	// The pod settings a deployment template leaves out, such as the security contexts, the
+	// ServiceAccount, scheduling, sidecars and image pull secrets, are taken from the Application
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// Overrides patch the generated resources before they are applied, for fields the
+	// Application does not support. Names, namespaces, selectors and pod labels cannot be
+	// changed, nor can the images, security contexts, host namespaces, hostPath volumes and
+	// containers of the pod template
+	// +optional
+	// +listType=atomic
+	Overrides []Override `json:"overrides,omitempty"`
+
+	// AdoptionPolicy decides what happens when a resource the Application generates already
+	// exists without being controlled by it. Never reports a conflict, IfUnowned takes control
+	// of resources without a controller, Force also takes control of resources controlled by
+	// another owner
+	// +optional
+	// +kubebuilder:validation:Enum=Never;IfUnowned;Force
+	// +kubebuilder:default=Never
+	AdoptionPolicy string `json:"adoptionPolicy,omitempty"`
+
 	// Expose defines a service which exposes the application
 	Expose *Expose `json:"expose"`
 }
 
+// ExtraContainer is a container added to the pods of an application, either a
+// full container spec or a preset defined in the operator configuration. The
+// security context of the application applies unless the container sets its own
+// +kubebuilder:validation:XValidation:rule="has(self.preset) != has(self.container)",message="exactly one of preset or container must be set"
+type ExtraContainer struct {
+	// Preset is the name of a container preset defined in the operator configuration
+	// +optional
+	Preset string `json:"preset,omitempty"`
+
+	// Container is the spec of the container. It is left out of the schema to
+	// keep the CRD small enough for client-side apply, and validated when the
+	// workload is created
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Container *corev1.Container `json:"container,omitempty"`
+}
+
+// Override patches the generated resources of a kind
+// +kubebuilder:validation:XValidation:rule="has(self.patch) || has(self.jsonPatch)",message="one of patch or jsonPatch must be set"
+type Override struct {
+	// Kind is the kind of the patched resources
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet;Service;Ingress
+	Kind string `json:"kind"`
+
+	// Patch is a strategic merge patch
+	// +optional
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Patch *apiextensionsv1.JSON `json:"patch,omitempty"`
+
+	// JSONPatch is a list of RFC 6902 JSON patch operations applied after patch
+	// +optional
+	JSONPatch []JSONPatchOperation `json:"jsonPatch,omitempty"`
+}
+
+// JSONPatchOperation is an RFC 6902 JSON patch operation
+type JSONPatchOperation struct {
+	// Op is the operation
+	// +kubebuilder:validation:Enum=add;remove;replace;move;copy;test
+	Op string `json:"op"`
+
+	// Path is the JSON pointer to the target of the operation
+	Path string `json:"path"`
+
+	// From is the JSON pointer to the source of a move or copy operation
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// Value is the value of an add, replace or test operation
+	// +optional
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
+// ConfigFromSource refers to a ConfigMap or a Secret exposed as environment variables
+// +kubebuilder:validation:XValidation:rule="has(self.configMap) != has(self.secret)",message="exactly one of configMap or secret must be set"
+type ConfigFromSource struct {
+	// ConfigMap is the name of a ConfigMap in the application namespace
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// Secret is the name of a Secret in the application namespace
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// Prefix is prepended to every environment variable name
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// ConfigVolume refers to a ConfigMap or a Secret mounted as files
+// +kubebuilder:validation:XValidation:rule="has(self.configMap) != has(self.secret)",message="exactly one of configMap or secret must be set"
+type ConfigVolume struct {
+	// Name is the name of the volume in the pod
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// ConfigMap is the name of a ConfigMap in the application namespace
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// Secret is the name of a Secret in the application namespace
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// MountPath is the directory in the container where the keys are mounted as files
+	MountPath string `json:"mountPath"`
+}
+
+// UpdateStrategy defines the rolling update of an application
+type UpdateStrategy struct {
+	// MaxUnavailable is the maximum number or percentage of pods that can be unavailable during the update
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MaxSurge is the maximum number or percentage of pods that can be created above the desired number during the update
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+}
+
+// Security profiles of an Application
+const (
+	SecurityProfileRestricted = "restricted"
+	SecurityProfileBaseline   = "baseline"
+)
+
+// Security defines the security context of an application, the fields which
+// are set override the defaults of the profile
+type Security struct {
+	// Profile is the hardening applied by default. restricted runs as non-root with all
+	// capabilities dropped, a read-only root filesystem with a writable /tmp and the
+	// RuntimeDefault seccomp profile, baseline applies no defaults
+	// +optional
+	// +kubebuilder:default=restricted
+	// +kubebuilder:validation:Enum=restricted;baseline
+	Profile string `json:"profile,omitempty"`
+
+	// RunAsNonRoot requires the container to run as a non-root user
+	// +optional
+	RunAsNonRoot *bool `json:"runAsNonRoot,omitempty"`
+
+	// RunAsUser is the UID the container runs as
+	// +optional
+	RunAsUser *int64 `json:"runAsUser,omitempty"`
+
+	// RunAsGroup is the GID the container runs as
+	// +optional
+	RunAsGroup *int64 `json:"runAsGroup,omitempty"`
+
+	// FSGroup is the group owning the pod volumes
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// ReadOnlyRootFilesystem mounts the root filesystem of the container read-only
+	// +optional
+	ReadOnlyRootFilesystem *bool `json:"readOnlyRootFilesystem,omitempty"`
+
+	// AllowPrivilegeEscalation allows a process to gain more privileges than its parent
+	// +optional
+	AllowPrivilegeEscalation *bool `json:"allowPrivilegeEscalation,omitempty"`
+
+	// Privileged runs the container in privileged mode
+	// +optional
+	Privileged *bool `json:"privileged,omitempty"`
+
+	// Capabilities are the capabilities added to and dropped from the container
+	// +optional
+	Capabilities *corev1.Capabilities `json:"capabilities,omitempty"`
+
+	// SeccompProfile is the seccomp profile of the pod
+	// +optional
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+}
+
+// ServiceAccount defines the ServiceAccount of an application
+type ServiceAccount struct {
+	// Name is the name of the ServiceAccount, the application name is used if empty
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name,omitempty"`
+
+	// Create creates a ServiceAccount owned by the application, otherwise an existing
+	// ServiceAccount is used and neither annotations nor roles are applied
+	// +optional
+	// +kubebuilder:default=true
+	Create *bool `json:"create,omitempty"`
+
+	// Annotations are set on the created ServiceAccount, e.g. for workload identity
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Roles are bound to the created ServiceAccount in the application namespace,
+	// ClusterRoles are bound with a RoleBinding as well. They are refused when the admission
+	// webhook is disabled
+	// +optional
+	// +listType=atomic
+	Roles []RoleRef `json:"roles,omitempty"`
+
+	// AutomountServiceAccountToken mounts the ServiceAccount token into the pods
+	// +optional
+	AutomountServiceAccountToken *bool `json:"automountServiceAccountToken,omitempty"`
+}
+
+// RoleRef refers to a Role or a ClusterRole
+type RoleRef struct {
+	// Kind is the kind of the role, Role or ClusterRole
+	// +kubebuilder:validation:Enum=Role;ClusterRole
+	Kind string `json:"kind"`
+
+	// Name is the name of the role
+	Name string `json:"name"`
+}
+
+// ImageUpdate defines which tags of the image repository the application is updated to
+type ImageUpdate struct {
+	// Semver is the range of versions the tags must satisfy, such as 1.4.x or >=1.4.0 <2.0.0.
+	// Tags are compared as semantic versions, a leading v is ignored
+	Semver string `json:"semver"`
+
+	// Filter is a regular expression the tags must match. Tags with a pre-release
+	// version, such as 1.4.2-rc.1 or 1.4.2-alpine, are only considered when it is set
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// Interval is how often the tags of the repository are listed
+	// +kubebuilder:default="5m"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// Hooks define Jobs run around the rollout of an application
+type Hooks struct {
+	// PreDeploy Jobs run in order whenever the image changes, including the first deployment.
+	// The workload keeps the previous image until all of them succeed, a failed Job marks
+	// the application Degraded and is retried once it is deleted
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	PreDeploy []HookJob `json:"preDeploy,omitempty"`
+}
+
+// HookJob defines a Job run with the image, env and config of an application
+type HookJob struct {
+	// Name is the name of the hook
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Image is the image of the Job, the application image is used if empty
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Command is the entrypoint of the Job
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments of the Job
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// Env is appended to the environment variables of the application
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// BackoffLimit is the number of retries before the Job is considered failed
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// ActiveDeadlineSeconds is the duration the Job may run before it is considered failed
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+}
+
+// CronJob defines a periodic task of an application
+type CronJob struct {
+	// Name is the name of the task
+	// +kubebuilder:validation:MaxLength=30
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Schedule is the schedule of the task in Cron format
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Command is the entrypoint of the task
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments of the task
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// ConcurrencyPolicy defines how concurrent runs of the task are handled
+	// +optional
+	// +kubebuilder:default=Forbid
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	ConcurrencyPolicy batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// SuccessfulJobsHistoryLimit is the number of successful runs kept
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// FailedJobsHistoryLimit is the number of failed runs kept
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// Dependency refers to an Application another application depends on
+type Dependency struct {
+	// Name is the name of the Application
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Application, the application namespace is used if empty
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NetworkPolicy defines the traffic allowed to and from an application
+type NetworkPolicy struct {
+	// From lists the sources allowed to connect to the application port,
+	// in Ingress mode the ingress controller namespace is allowed as well
+	// +optional
+	From []NetworkPeer `json:"from,omitempty"`
+
+	// To lists the destinations the application is allowed to connect to, DNS is
+	// always allowed. Egress is not restricted if it is unset
+	// +optional
+	To []EgressRule `json:"to,omitempty"`
+
+	// IngressControllerNamespace is the namespace of the ingress controller
+	// +optional
+	// +kubebuilder:default=ingress-nginx
+	IngressControllerNamespace string `json:"ingressControllerNamespace,omitempty"`
+}
+
+// NetworkPeer refers to the pods of an Application, the pods of a namespace or an IP block
+// +kubebuilder:validation:XValidation:rule="has(self.cidr) != (has(self.application) || has(self.namespace))",message="exactly one of cidr or application and namespace must be set"
+type NetworkPeer struct {
+	// Application is the name of an Application, in the application namespace unless namespace is set
+	// +optional
+	Application string `json:"application,omitempty"`
+
+	// Namespace is the name of a namespace, all its pods are selected unless application is set
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// CIDR is an IP block
+	// +optional
+	CIDR string `json:"cidr,omitempty"`
+
+	// Except lists the IP blocks excluded from cidr
+	// +optional
+	Except []string `json:"except,omitempty"`
+}
+
+// EgressRule defines a destination an application is allowed to connect to
+type EgressRule struct {
+	NetworkPeer `json:",inline"`
+
+	// Ports restricts the destination ports, all ports are allowed if empty
+	// +optional
+	Ports []networkingv1.NetworkPolicyPort `json:"ports,omitempty"`
+}
+
+// Persistence defines the persistent volumes of an application
+type Persistence struct {
+	// VolumeClaimTemplates is a list of claims, each pod gets its own volume per claim
+	// +listType=map
+	// +listMapKey=name
+	VolumeClaimTemplates []VolumeClaimTemplate `json:"volumeClaimTemplates"`
+}
+
+// VolumeClaimTemplate defines a persistent volume claim mounted into the application
+type VolumeClaimTemplate struct {
+	// Name is the name of the claim and of the volume in the pod
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// MountPath is the directory in the container where the volume is mounted
+	MountPath string `json:"mountPath"`
+
+	// Size is the requested storage size
+	Size resource.Quantity `json:"size"`
+
+	// StorageClassName is the storage class of the claim, the cluster default is used if empty
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessModes are the access modes of the claim, ReadWriteOnce if empty
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
 // Expose defines a service which exposes an application
 type Expose struct {
 	// Mode defines the service mode, NodePort or Ingress
@@ -77,6 +642,22 @@ type Expose struct {
 	// ServicePort is a port number used by the service
 	// +optional
 	ServicePort int32 `json:"servicePort,omitempty"`
+
+	// MaintenanceService is the Service of a maintenance page the Ingress routes to while the
+	// application is suspended, the Ingress keeps routing to the application if unset
+	// +optional
+	MaintenanceService *MaintenanceService `json:"maintenanceService,omitempty"`
+}
+
+// MaintenanceService refers to a Service of the application namespace serving a maintenance page
+type MaintenanceService struct {
+	// Name is the name of the Service
+	Name string `json:"name"`
+
+	// Port is the port of the Service
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
 }
 
 // ApplicationStatus defines the observed state of Application.
@@ -96,6 +677,37 @@ type ApplicationStatus struct {
 	// Reason indicates details about why the application is in this state.
 	Reason string `json:"reason"`
 
+	// WorkloadKind is the kind of workload currently running the application
+	// +optional
+	WorkloadKind string `json:"workloadKind,omitempty"`
+
+	// Replicas is the desired number of pods, for a DaemonSet the number of nodes which should run it
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the number of ready pods
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// UpdatedReplicas is the number of pods running the latest pod template
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// ResolvedImage is the image of the application pinned to the digest its tag resolved to,
+	// it is set when the image policy of the operator resolves digests
+	// +optional
+	ResolvedImage string `json:"resolvedImage,omitempty"`
+
+	// ImageUpdate reports the image updates made by the operator
+	// +optional
+	ImageUpdate *ImageUpdateStatus `json:"imageUpdate,omitempty"`
+
+	// Ordinals reports the readiness of each pod of a StatefulSet application
+	// +optional
+	// +listType=map
+	// +listMapKey=ordinal
+	Ordinals []OrdinalStatus `json:"ordinals,omitempty"`
+
 	// Conditions represent the current state of the Application resource.
 	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
 	//
@@ -111,6 +723,37 @@ type ApplicationStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// ImageUpdateStatus reports the image updates made by the operator
+type ImageUpdateStatus struct {
+	// LastCheckTime is when the tags of the image repository were last listed
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// LastUpdateTime is when the image was last updated
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Image is the image set by the last update
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// PreviousImage is the image replaced by the last update
+	// +optional
+	PreviousImage string `json:"previousImage,omitempty"`
+}
+
+// OrdinalStatus is the status of the pod of a StatefulSet with a given ordinal
+type OrdinalStatus struct {
+	// Ordinal is the ordinal of the pod
+	Ordinal int32 `json:"ordinal"`
+
+	// Pod is the name of the pod
+	Pod string `json:"pod"`
+
+	// Ready reports whether the pod exists and is ready
+	Ready bool `json:"ready"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 
@@ -0,0 +1,218 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pmezard/go-difflib/difflib"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+)
+
+// errChangesPending is returned by diff when the live objects differ from the
+// rendered ones.
+var errChangesPending = errors.New("changes pending")
+
+func runDiff(args []string) error {
+	var f renderFlags
+	var kubeconfig string
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	f.bind(fs)
+	bindKubeconfig(fs, &kubeconfig)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: appctl diff -f FILE [flags]\n\n"+
+			"Prints the difference between the live objects of the Applications of the files and the\n"+
+			"objects the operator would apply, as a unified diff per object. Only the fields the operator\n"+
+			"compares are shown. Exits with 1 when there are changes pending.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(f.files) == 0 {
+		fs.Usage()
+		return errors.New("no file given")
+	}
+	opts, err := f.options()
+	if err != nil {
+		return err
+	}
+	// The ConfigMaps and Secrets of the files are ignored, the ones of the
+	// cluster are used like the operator does.
+	applications, _, err := readObjects(f.files, f.namespace)
+	if err != nil {
+		return err
+	}
+	c, err := newClient(kubeconfig)
+	if err != nil {
+		return err
+	}
+
+	changed, err := diffApplications(context.Background(), c, applications, opts, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if changed {
+		return errChangesPending
+	}
+	return nil
+}
+
+// diffApplications writes the diff of every object of the applications to out
+// and reports whether any of them has changes pending.
+func diffApplications(ctx context.Context, c client.Client, applications []*appsv1alpha1.Application,
+	opts apps.RenderOptions, out io.Writer) (bool, error) {
+	changed := false
+	for _, app := range applications {
+		rendered, err := apps.Render(ctx, c, app, opts)
+		if err != nil {
+			return false, fmt.Errorf("Application %s/%s: %w", app.Namespace, app.Name, err)
+		}
+		for _, obj := range rendered {
+			diff, err := diffObject(ctx, c, obj)
+			if err != nil {
+				return false, err
+			}
+			if diff != "" {
+				changed = true
+				if _, err := io.WriteString(out, diff); err != nil {
+					return false, err
+				}
+			}
+		}
+	}
+	return changed, nil
+}
+
+// diffObject returns the unified diff between the live object and the
+// desired one, or an empty string if the operator would not change it.
+func diffObject(ctx context.Context, c client.Client, desired client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(desired, scheme)
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s/%s/%s", gvk.Kind, desired.GetNamespace(), desired.GetName())
+	live, err := scheme.New(gvk)
+	if err != nil {
+		return "", err
+	}
+	liveObj := live.(client.Object)
+	var liveFields map[string]any
+	switch err := c.Get(ctx, client.ObjectKeyFromObject(desired), liveObj); {
+	case apierrors.IsNotFound(err):
+		liveObj = nil
+	case err != nil:
+		return "", err
+	}
+
+	if liveObj != nil {
+		switch desired.(type) {
+		case *corev1.ConfigMap, *batchv1.Job:
+			// Named after their content, they are never updated.
+			return "", nil
+		case *corev1.ServiceAccount, *rbacv1.RoleBinding:
+			// Compared as they are by the operator.
+		default:
+			// The operator compares the result of a dry-run update, so that
+			// the fields defaulted by the API server do not show up.
+			if err := c.Update(ctx, desired, client.DryRunAll); err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if liveFields, err = comparedFields(liveObj); err != nil {
+			return "", err
+		}
+	}
+	desiredFields, err := comparedFields(desired)
+	if err != nil {
+		return "", err
+	}
+
+	a, err := marshalFields(liveFields)
+	if err != nil {
+		return "", err
+	}
+	b, err := marshalFields(desiredFields)
+	if err != nil {
+		return "", err
+	}
+	if a == b {
+		return "", nil
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: "live/" + name,
+		ToFile:   "desired/" + name,
+		Context:  3,
+	})
+}
+
+// comparedFields returns the fields of an object the operator compares with
+// the live object to decide whether to update it: the labels and everything
+// but the metadata and status, the labels and annotations of ServiceAccounts.
+func comparedFields(obj client.Object) (map[string]any, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	metadata := map[string]any{}
+	if labels, ok, _ := unstructured.NestedFieldNoCopy(u, "metadata", "labels"); ok {
+		metadata["labels"] = labels
+	}
+	if _, ok := obj.(*corev1.ServiceAccount); ok {
+		if annotations, ok, _ := unstructured.NestedFieldNoCopy(u, "metadata", "annotations"); ok {
+			metadata["annotations"] = annotations
+		}
+		fields["metadata"] = metadata
+		return fields, nil
+	}
+	for k, v := range u {
+		switch k {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+		fields[k] = v
+	}
+	fields["metadata"] = metadata
+	return fields, nil
+}
+
+func marshalFields(fields map[string]any) (string, error) {
+	if fields == nil {
+		return "", nil
+	}
+	b, err := sigsyaml.Marshal(fields)
+	return string(b), err
+}
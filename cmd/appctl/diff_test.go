@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+)
+
+// TestDiff runs against envtest, set up by make test.
+func TestDiff(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set")
+	}
+	env := &envtest.Environment{}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Stop() //nolint:errcheck
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-test"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	applications, _, err := readObjects([]string{"../../internal/controller/apps/testdata/app_ing_cr.yaml"}, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := func() (bool, string) {
+		out := &bytes.Buffer{}
+		changed, err := diffApplications(ctx, c, applications, apps.RenderOptions{}, out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changed, out.String()
+	}
+
+	changed, out := diff()
+	if !changed || !strings.Contains(out, "+++ desired/Deployment/my-test/my-test-ing") {
+		t.Fatalf("got changed %v, diff:\n%s", changed, out)
+	}
+
+	rendered, err := apps.Render(ctx, c, applications[0], apps.RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, obj := range rendered {
+		if err := c.Create(ctx, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if changed, out := diff(); changed {
+		t.Fatalf("got changes after creating the objects:\n%s", out)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "my-test", Name: "my-test-ing"}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	deployment.Spec.Replicas = ptr.To[int32](5)
+	if err := c.Update(ctx, deployment); err != nil {
+		t.Fatal(err)
+	}
+	changed, out = diff()
+	if !changed || !strings.Contains(out, "-  replicas: 5") || !strings.Contains(out, "+  replicas: 2") {
+		t.Errorf("got changed %v, diff:\n%s", changed, out)
+	}
+	if strings.Contains(out, "Service/") {
+		t.Errorf("got a diff for unchanged objects:\n%s", out)
+	}
+}
+
+func TestComparedFields(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:            "web",
+		Labels:          map[string]string{"app": "web"},
+		Annotations:     map[string]string{"deployment.kubernetes.io/revision": "3"},
+		ResourceVersion: "42",
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+		Status:     appsv1.DeploymentStatus{Replicas: 2},
+	}
+	fields, err := comparedFields(deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := marshalFields(fields)
+	want := "metadata:\n  labels:\n    app: web\nspec:\n  replicas: 2\n  selector: null\n" +
+		"  strategy: {}\n  template:\n    metadata:\n      creationTimestamp: null\n    spec:\n      containers: null\n"
+	if got != want {
+		t.Errorf("got Deployment fields:\n%s\nwant:\n%s", got, want)
+	}
+
+	sa := &corev1.ServiceAccount{ObjectMeta: meta, AutomountServiceAccountToken: ptr.To(false)}
+	fields, err = comparedFields(sa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = marshalFields(fields)
+	want = "metadata:\n  annotations:\n    deployment.kubernetes.io/revision: \"3\"\n  labels:\n    app: web\n"
+	if got != want {
+		t.Errorf("got ServiceAccount fields:\n%s\nwant:\n%s", got, want)
+	}
+}
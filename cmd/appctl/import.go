@@ -0,0 +1,210 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+	"github.com/yanxinfire/application-management-operator/internal/importer"
+)
+
+func runImport(args []string) error {
+	var f renderFlags
+	var kubeconfig, selector string
+	var adopt bool
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	f.bind(fs)
+	bindKubeconfig(fs, &kubeconfig)
+	fs.StringVar(&selector, "selector", "", "Only import the Deployments matching this label selector.")
+	fs.BoolVar(&adopt, "adopt", false, "Create the Applications in the cluster and make them the controller "+
+		"owner of the imported objects. Their pods are not recreated by the adoption itself.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: appctl import [-f FILE] [flags]\n\n"+
+			"Prints the Applications equivalent to the Deployments, and the Services and Ingresses exposing\n"+
+			"them, of the files or of the namespace of the cluster when no file is given. The settings an\n"+
+			"Application cannot represent are listed as comments above it.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return err
+	}
+	if adopt && len(f.files) > 0 {
+		return errors.New("--adopt imports the objects of the cluster, no file can be given")
+	}
+
+	ctx := context.Background()
+	var c client.Client
+	var deployments []appsv1.Deployment
+	var services []corev1.Service
+	var ingresses []networkingv1.Ingress
+	if len(f.files) > 0 {
+		objects, err := decodeFiles(f.files, f.namespace)
+		if err != nil {
+			return err
+		}
+		for _, obj := range objects {
+			switch obj := obj.(type) {
+			case *appsv1.Deployment:
+				if sel.Matches(labels.Set(obj.Labels)) {
+					deployments = append(deployments, *obj)
+				}
+			case *corev1.Service:
+				services = append(services, *obj)
+			case *networkingv1.Ingress:
+				ingresses = append(ingresses, *obj)
+			}
+		}
+	} else {
+		if c, err = newClient(kubeconfig); err != nil {
+			return err
+		}
+		if deployments, services, ingresses, err = listObjects(ctx, c, f.namespace, sel); err != nil {
+			return err
+		}
+	}
+
+	results := importer.Import(deployments, services, ingresses)
+	if err := writeResults(os.Stdout, results); err != nil {
+		return err
+	}
+	if !adopt {
+		return nil
+	}
+	opts, err := f.options()
+	if err != nil {
+		return err
+	}
+	var failed int
+	for _, r := range results {
+		if err := adoptObjects(ctx, c, r, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Deployment %s/%s is not adopted: %v\n", r.Deployment.Namespace, r.Deployment.Name, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d Deployments could not be adopted", failed, len(results))
+	}
+	return nil
+}
+
+func listObjects(ctx context.Context, c client.Client, namespace string, sel labels.Selector) (
+	[]appsv1.Deployment, []corev1.Service, []networkingv1.Ingress, error) {
+	deployments := &appsv1.DeploymentList{}
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if sel != nil {
+		opts = append(opts, client.MatchingLabelsSelector{Selector: sel})
+	}
+	if err := c.List(ctx, deployments, opts...); err != nil {
+		return nil, nil, nil, err
+	}
+	services := &corev1.ServiceList{}
+	if err := c.List(ctx, services, client.InNamespace(namespace)); err != nil {
+		return nil, nil, nil, err
+	}
+	ingresses := &networkingv1.IngressList{}
+	if err := c.List(ctx, ingresses, client.InNamespace(namespace)); err != nil {
+		return nil, nil, nil, err
+	}
+	return deployments.Items, services.Items, ingresses.Items, nil
+}
+
+// writeResults writes the Applications with their issues and conflicts as
+// comments.
+func writeResults(w io.Writer, results []*importer.Result) error {
+	out := bufio.NewWriter(w)
+	for i, r := range results {
+		if i > 0 {
+			fmt.Fprintln(out, "---")
+		}
+		for _, conflict := range r.Conflicts {
+			fmt.Fprintf(out, "# CONFLICT: %s\n", conflict)
+		}
+		for _, issue := range r.Issues {
+			fmt.Fprintf(out, "# %s\n", issue)
+		}
+		b, err := marshalObject(r.Application)
+		if err != nil {
+			return err
+		}
+		if _, err := out.Write(b); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// adoptObjects creates the Application of the result and makes it the
+// controller owner of the imported objects. The objects are only patched, the
+// operator then reconciles them like any other; the changes it is going to
+// make are printed beforehand.
+func adoptObjects(ctx context.Context, c client.Client, r *importer.Result, opts apps.RenderOptions) error {
+	if len(r.Conflicts) > 0 {
+		return fmt.Errorf("%d conflicts", len(r.Conflicts))
+	}
+	app := r.Application.DeepCopy()
+	rendered, err := apps.Render(ctx, c, app, opts)
+	if err != nil {
+		return err
+	}
+	for _, obj := range rendered {
+		diff, err := diffObject(ctx, c, obj)
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			fmt.Fprintf(os.Stderr, "The operator is going to update %s %s/%s once adopted:\n%s",
+				obj.GetObjectKind().GroupVersionKind().Kind, obj.GetNamespace(), obj.GetName(), diff)
+		}
+	}
+
+	if err := c.Create(ctx, app); err != nil {
+		return err
+	}
+	for _, obj := range []client.Object{r.Deployment, r.Service, r.Ingress} {
+		if reflect.ValueOf(obj).IsNil() {
+			continue
+		}
+		patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+		if err := controllerutil.SetControllerReference(app, obj, scheme); err != nil {
+			return err
+		}
+		if err := c.Patch(ctx, obj, patch); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Adopted %s %s/%s\n", reflect.TypeOf(obj).Elem().Name(), obj.GetNamespace(), obj.GetName())
+	}
+	return nil
+}
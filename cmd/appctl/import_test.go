@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+	"github.com/yanxinfire/application-management-operator/internal/importer"
+)
+
+// TestAdoptObjects runs against envtest, set up by make test.
+func TestAdoptObjects(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set")
+	}
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := env.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.Stop() //nolint:errcheck
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}); err != nil {
+		t.Fatal(err)
+	}
+	objects, err := decodeFiles([]string{"../../internal/importer/testdata/web.yaml"}, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, obj := range objects {
+		if err := c.Create(ctx, obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deployments, services, ingresses, err := listObjects(ctx, c, "shop", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := importer.Import(deployments, services, ingresses)
+	for _, r := range results {
+		err := adoptObjects(ctx, c, r, apps.RenderOptions{})
+		if r.Deployment.Name == "worker" {
+			if err == nil {
+				t.Errorf("adopted the conflicting worker Deployment")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "shop", Name: "web"}, deployment); err != nil {
+		t.Fatal(err)
+	}
+	owner := metav1.GetControllerOf(deployment)
+	if owner == nil || owner.Kind != "Application" || owner.Name != "web" {
+		t.Errorf("got controller %v", owner)
+	}
+	// Only the metadata changed, the pods are not rolled.
+	if deployment.Generation != 1 {
+		t.Errorf("got generation %d", deployment.Generation)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command appctl works with Application manifests outside of the operator.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(appsv1alpha1.AddToScheme(scheme))
+}
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "render", usage: "Print the objects the operator creates for Applications", run: runRender},
+	{name: "diff", usage: "Diff the live objects of Applications against the rendered ones", run: runDiff},
+	{name: "import", usage: "Import Deployments, Services and Ingresses into Applications", run: runImport},
+}
+
+func bindKubeconfig(fs *flag.FlagSet, kubeconfig *string) {
+	fs.StringVar(kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, the default loading rules apply if empty.")
+}
+
+// newClient returns a client of the cluster of the kubeconfig.
+func newClient(kubeconfig string) (client.Client, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: appctl <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'appctl <command> -h' for the flags of a command.\n"+
+		"The exit code is 1 when diff finds changes pending, 2 on errors.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		err := c.run(os.Args[2:])
+		switch {
+		case errors.Is(err, errChangesPending):
+			os.Exit(1)
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "appctl %s: %v\n", c.name, err)
+			os.Exit(2)
+		}
+		return
+	}
+	usage()
+	os.Exit(2)
+}
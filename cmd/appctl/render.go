@@ -0,0 +1,228 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+)
+
+// stringsFlag is a flag which can be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// renderFlags are the flags of the commands rendering Applications.
+type renderFlags struct {
+	files                stringsFlag
+	namespace            string
+	containerPresetsFile string
+	templateNamespace    string
+	registryCredentials  string
+}
+
+func (f *renderFlags) bind(fs *flag.FlagSet) {
+	fs.Var(&f.files, "f", "A file holding Applications, and the ConfigMaps and Secrets they use, "+
+		"- for the standard input. Can be repeated.")
+	fs.StringVar(&f.namespace, "namespace", "default", "The namespace of the Applications which have none.")
+	fs.StringVar(&f.containerPresetsFile, "container-presets", "",
+		"Path to the container presets file of the operator.")
+	fs.StringVar(&f.templateNamespace, "template-namespace", "application-management-operator-system",
+		"The namespace of the template ConfigMaps, as configured on the operator.")
+	fs.StringVar(&f.registryCredentials, "registry-credentials", "",
+		"The name of the registry credential Secret copied by the operator, if configured.")
+}
+
+// options returns the render options matching the operator's configuration.
+func (f *renderFlags) options() (apps.RenderOptions, error) {
+	opts := apps.RenderOptions{
+		TemplateNamespace:   f.templateNamespace,
+		RegistryCredentials: f.registryCredentials,
+	}
+	if f.containerPresetsFile != "" {
+		presets, err := apps.LoadContainerPresets(f.containerPresetsFile)
+		if err != nil {
+			return opts, err
+		}
+		opts.ContainerPresets = presets
+	}
+	return opts, nil
+}
+
+func runRender(args []string) error {
+	var f renderFlags
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	f.bind(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: appctl render -f FILE [flags]\n\n"+
+			"Prints the objects the operator creates for the Applications of the files, without cluster\n"+
+			"access. ConfigMaps and Secrets of the files are used for the template sets and config\n"+
+			"checksums, as if they existed in the cluster.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(f.files) == 0 {
+		fs.Usage()
+		return errors.New("no file given")
+	}
+	opts, err := f.options()
+	if err != nil {
+		return err
+	}
+	applications, objects, err := readObjects(f.files, f.namespace)
+	if err != nil {
+		return err
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+	ctx := context.Background()
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush() //nolint:errcheck
+	for i, app := range applications {
+		rendered, err := apps.Render(ctx, reader, app, opts)
+		if err != nil {
+			return fmt.Errorf("Application %s/%s: %w", app.Namespace, app.Name, err)
+		}
+		for j, obj := range rendered {
+			if i > 0 || j > 0 {
+				fmt.Fprintln(out, "---")
+			}
+			b, err := marshalObject(obj)
+			if err != nil {
+				return err
+			}
+			if _, err := out.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readObjects reads the Applications of the files, and the ConfigMaps and
+// Secrets next to them. The other objects are ignored.
+func readObjects(files []string, namespace string) ([]*appsv1alpha1.Application, []client.Object, error) {
+	decoded, err := decodeFiles(files, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	var applications []*appsv1alpha1.Application
+	var objects []client.Object
+	for _, obj := range decoded {
+		switch obj := obj.(type) {
+		case *appsv1alpha1.Application:
+			applications = append(applications, obj)
+		case *corev1.ConfigMap, *corev1.Secret:
+			objects = append(objects, obj)
+		}
+	}
+	return applications, objects, nil
+}
+
+// decodeFiles returns the objects of the files whose kind is known, in the
+// namespace when they have none.
+func decodeFiles(files []string, namespace string) ([]client.Object, error) {
+	var objects []client.Object
+	for _, name := range files {
+		var r io.Reader = os.Stdin
+		if name != "-" {
+			file, err := os.Open(name)
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close() //nolint:errcheck
+			r = file
+		}
+		decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+		for {
+			u := &unstructured.Unstructured{}
+			if err := decoder.Decode(&u.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if len(u.Object) == 0 || !scheme.Recognizes(u.GroupVersionKind()) {
+				continue
+			}
+			if u.GetNamespace() == "" {
+				u.SetNamespace(namespace)
+			}
+			typed, err := scheme.New(u.GroupVersionKind())
+			if err != nil {
+				return nil, err
+			}
+			obj, ok := typed.(client.Object)
+			if !ok {
+				continue
+			}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+				return nil, fmt.Errorf("%s: %s %s: %w", name, u.GetKind(), u.GetName(), err)
+			}
+			if secret, ok := obj.(*corev1.Secret); ok {
+				// Like the API server, so that the config checksum is the same.
+				for k, v := range secret.StringData {
+					if secret.Data == nil {
+						secret.Data = map[string][]byte{}
+					}
+					secret.Data[k] = []byte(v)
+				}
+				secret.StringData = nil
+			}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// marshalObject returns the YAML of an object without the fields the API
+// server sets.
+func marshalObject(obj client.Object) ([]byte, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	delete(u, "status")
+	return sigsyaml.Marshal(u)
+}
@@ -19,13 +19,19 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -37,6 +43,11 @@ import (
 
 	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
 	appscontroller "github.com/yanxinfire/application-management-operator/internal/controller/apps"
+	"github.com/yanxinfire/application-management-operator/internal/imagepolicy"
+	"github.com/yanxinfire/application-management-operator/internal/permissions"
+	"github.com/yanxinfire/application-management-operator/internal/registry"
+	"github.com/yanxinfire/application-management-operator/internal/sharding"
+	webhookappsv1alpha1 "github.com/yanxinfire/application-management-operator/internal/webhook/apps/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -61,6 +72,19 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var maxConcurrentReconciles int
+	var rateLimiterQPS float64
+	var rateLimiterBurst int
+	var watchNamespaces string
+	var applicationLabelSelector string
+	var cacheOwnedObjectsOnly bool
+	var enableSharding bool
+	var shardID, shardNamespace string
+	var shardLeaseDuration time.Duration
+	var containerPresetsFile string
+	var imagePolicyFile, insecureRegistries string
+	var registryCredentials string
+	var templateNamespace string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -79,6 +103,46 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The maximum number of Applications which can be reconciled concurrently.")
+	flag.Float64Var(&rateLimiterQPS, "rate-limiter-qps", 10,
+		"The overall number of reconcile requests per second allowed by the rate limiter.")
+	flag.IntVar(&rateLimiterBurst, "rate-limiter-burst", 100,
+		"The burst size of the reconcile rate limiter.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma separated list of namespaces to watch. All namespaces are watched if empty. "+
+			"The ConfigMaps and Secrets of the template namespace and POD_NAMESPACE are watched as well.")
+	flag.StringVar(&applicationLabelSelector, "application-label-selector", "",
+		"Only reconcile Applications matching this label selector, e.g. 'team=payments'.")
+	flag.BoolVar(&cacheOwnedObjectsOnly, "cache-owned-objects-only", false,
+		"If set, only the Deployments, StatefulSets, DaemonSets, Services, Ingresses, NetworkPolicies, Jobs, "+
+			"CronJobs, ServiceAccounts and RoleBindings labelled by the operator are cached, "+
+			"which reduces memory usage on large clusters.")
+	flag.BoolVar(&enableSharding, "enable-sharding", false,
+		"Enable sharding Applications across all operator replicas instead of leader election. "+
+			"Each replica reconciles the Applications of its own shard.")
+	flag.StringVar(&shardID, "shard-id", os.Getenv("POD_NAME"),
+		"The unique ID of this replica among the shard members, defaults to the POD_NAME environment variable.")
+	flag.StringVar(&shardNamespace, "shard-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace holding the shard member leases, defaults to the POD_NAMESPACE environment variable.")
+	flag.DurationVar(&shardLeaseDuration, "shard-lease-duration", 15*time.Second,
+		"The duration after which a replica which stopped renewing its lease leaves the shard members, "+
+			"at least one second.")
+	flag.StringVar(&templateNamespace, "template-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace of the template ConfigMaps selected by Applications, "+
+			"defaults to the POD_NAMESPACE environment variable.")
+	flag.StringVar(&containerPresetsFile, "container-presets", "",
+		"Path to a YAML file holding a list of containers which Applications can add by name "+
+			"as init containers or sidecars.")
+	flag.StringVar(&imagePolicyFile, "image-policy", "",
+		"Path to a YAML file holding the policy restricting the images of Applications, "+
+			"enforced by the webhook and the controller.")
+	flag.StringVar(&registryCredentials, "registry-credentials", "",
+		"The name of a registry credential Secret of the POD_NAMESPACE namespace, copied into the namespace "+
+			"of every Application and added to its image pull secrets.")
+	flag.StringVar(&insecureRegistries, "insecure-registries", "",
+		"Comma separated list of registries reached over plain HTTP when resolving image digests "+
+			"and listing image tags.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -154,8 +218,45 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
+	// The template ConfigMaps and the registry credentials are watched outside
+	// of the watched namespaces.
+	cacheConfig := appscontroller.CacheConfig{
+		ConfigNamespaces: []string{templateNamespace, os.Getenv("POD_NAMESPACE")},
+		OwnedObjectsOnly: cacheOwnedObjectsOnly,
+	}
+	for _, ns := range strings.Split(watchNamespaces, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			cacheConfig.Namespaces = append(cacheConfig.Namespaces, ns)
+		}
+	}
+	if applicationLabelSelector != "" {
+		selector, err := labels.Parse(applicationLabelSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid application label selector", "selector", applicationLabelSelector)
+			os.Exit(1)
+		}
+		cacheConfig.ApplicationSelector = selector
+	}
+
+	if enableSharding {
+		if shardID == "" || shardNamespace == "" {
+			setupLog.Error(nil, "--shard-id and --shard-namespace are required when sharding is enabled")
+			os.Exit(1)
+		}
+		// Leases are recorded in whole seconds.
+		if shardLeaseDuration < time.Second {
+			setupLog.Error(nil, "--shard-lease-duration must be at least one second", "duration", shardLeaseDuration)
+			os.Exit(1)
+		}
+		if enableLeaderElection {
+			setupLog.Info("sharding is enabled, disabling leader election")
+			enableLeaderElection = false
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheConfig.CacheOptions(),
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -178,13 +279,74 @@ func main() {
 		os.Exit(1)
 	}
 
+	var containerPresets map[string]corev1.Container
+	if containerPresetsFile != "" {
+		if containerPresets, err = appscontroller.LoadContainerPresets(containerPresetsFile); err != nil {
+			setupLog.Error(err, "unable to load container presets")
+			os.Exit(1)
+		}
+	}
+
+	var imagePolicy *imagepolicy.Policy
+	if imagePolicyFile != "" {
+		if imagePolicy, err = imagepolicy.Load(imagePolicyFile); err != nil {
+			setupLog.Error(err, "unable to load image policy")
+			os.Exit(1)
+		}
+	}
+	registryClient := &registry.Client{HTTPClient: &http.Client{Timeout: registry.RequestTimeout}}
+	if insecureRegistries != "" {
+		registryClient.Insecure = strings.Split(insecureRegistries, ",")
+	}
+
+	var registryCredentialsRef types.NamespacedName
+	if registryCredentials != "" {
+		registryCredentialsRef = types.NamespacedName{Namespace: os.Getenv("POD_NAMESPACE"), Name: registryCredentials}
+		if registryCredentialsRef.Namespace == "" {
+			setupLog.Error(nil, "the POD_NAMESPACE environment variable is required with --registry-credentials")
+			os.Exit(1)
+		}
+	}
+
+	required := appscontroller.RequiredPermissions()
+	var shard *sharding.Coordinator
+	if enableSharding {
+		required = append(required, sharding.RequiredPermissions(shardNamespace)...)
+		shard = sharding.NewCoordinator(mgr.GetClient(), mgr.GetAPIReader(),
+			shardNamespace, shardID, shardLeaseDuration)
+		if err := mgr.Add(shard); err != nil {
+			setupLog.Error(err, "unable to set up shard coordinator")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&appscontroller.ApplicationReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RateLimiter:             appscontroller.NewRateLimiter(rateLimiterQPS, rateLimiterBurst),
+		ContainerPresets:        containerPresets,
+		TemplateNamespace:       templateNamespace,
+		Shard:                   shard,
+		ImagePolicy:             imagePolicy,
+		ImageResolver:           registryClient,
+		ImageTagLister:          registryClient,
+		RegistryCredentials:     registryCredentialsRef,
+		Recorder:                mgr.GetEventRecorderFor("application-controller"),
+		RefuseRoles:             os.Getenv("ENABLE_WEBHOOKS") == "false",
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Application")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		required = append(required, webhookappsv1alpha1.RequiredPermissions()...)
+		if err := webhookappsv1alpha1.SetupApplicationWebhookWithManager(mgr, imagePolicy); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Application")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -195,9 +357,24 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	// The operator stays unready until it has been granted every permission
+	// its enabled features need.
+	checker := permissions.NewChecker(mgr.GetClient(), cacheConfig.Namespaces, required)
+	if err := mgr.AddReadyzCheck("permissions", checker.Check); err != nil {
+		setupLog.Error(err, "unable to set up permissions check")
+		os.Exit(1)
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	if missing, err := checker.Missing(ctx); err != nil {
+		setupLog.Error(err, "unable to check permissions")
+	} else if len(missing) > 0 {
+		setupLog.Error(permissions.MissingError(missing),
+			"operator RBAC is incomplete, readiness will fail until it is fixed")
+	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
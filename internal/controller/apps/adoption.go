@@ -0,0 +1,73 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// adoptionConflictReason is the status reason of an application whose
+// resources exist without being controlled by it.
+const adoptionConflictReason = "AdoptionConflict"
+
+// adopt checks whether the application may manage the existing object which
+// the desired one, controlled by the application, replaces. It returns a
+// heldError reporting the conflict if its adoption policy forbids it. The
+// owner references of the existing object other than its controller are
+// kept on the desired object.
+func (r *ApplicationReconciler) adopt(ctx context.Context, app *v1alpha1.Application, existing, desired client.Object) error {
+	kind := desired.GetObjectKind().GroupVersionKind().Kind
+	owner := metav1.GetControllerOf(existing)
+	if owner == nil || owner.UID != app.UID {
+		policy := app.Spec.AdoptionPolicy
+		switch {
+		case owner == nil && (policy == "" || policy == v1alpha1.AdoptionPolicyNever):
+			return &heldError{
+				phase:  v1alpha1.PhaseDegraded,
+				reason: adoptionConflictReason,
+				message: fmt.Sprintf("%s %s already exists and is not managed by the application, "+
+					"set adoptionPolicy to IfUnowned to adopt it", kind, existing.GetName()),
+			}
+		case owner != nil && policy != v1alpha1.AdoptionPolicyForce:
+			return &heldError{
+				phase:  v1alpha1.PhaseDegraded,
+				reason: adoptionConflictReason,
+				message: fmt.Sprintf("%s %s already exists and is controlled by %s %s, "+
+					"set adoptionPolicy to Force to take it over", kind, existing.GetName(), owner.Kind, owner.Name),
+			}
+		}
+		logf.FromContext(ctx).Info("Adopting "+kind, "Namespace", existing.GetNamespace(), "Name", existing.GetName(),
+			"AdoptionPolicy", policy)
+	}
+
+	refs := desired.GetOwnerReferences()
+	for _, ref := range existing.GetOwnerReferences() {
+		if ref.UID == app.UID || ref.Controller != nil && *ref.Controller {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	desired.SetOwnerReferences(refs)
+	return nil
+}
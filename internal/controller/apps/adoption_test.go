@@ -0,0 +1,96 @@
+package apps
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestAdopt(t *testing.T) {
+	other := metav1.OwnerReference{
+		APIVersion: "apps/v1", Kind: "Deployment", Name: "other", UID: "other-uid", Controller: ptr.To(true),
+	}
+	referrer := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "referrer", UID: "referrer-uid"}
+	tests := []struct {
+		name     string
+		policy   string
+		existing []metav1.OwnerReference
+		wantErr  bool
+		wantRefs int
+	}{
+		{name: "unowned, default policy", existing: []metav1.OwnerReference{referrer}, wantErr: true},
+		{name: "unowned, Never", policy: v1alpha1.AdoptionPolicyNever, wantErr: true},
+		{name: "unowned, IfUnowned", policy: v1alpha1.AdoptionPolicyIfUnowned,
+			existing: []metav1.OwnerReference{referrer}, wantRefs: 2},
+		{name: "owned by another, IfUnowned", policy: v1alpha1.AdoptionPolicyIfUnowned,
+			existing: []metav1.OwnerReference{other}, wantErr: true},
+		{name: "owned by another, Force", policy: v1alpha1.AdoptionPolicyForce,
+			existing: []metav1.OwnerReference{other, referrer}, wantRefs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+			app.UID = "app-uid"
+			app.Spec.AdoptionPolicy = tt.policy
+			r := &ApplicationReconciler{Scheme: clientgoscheme.Scheme}
+			existing := NewService(app)
+			existing.OwnerReferences = tt.existing
+			desired := NewService(app)
+			desired.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: v1alpha1.GroupVersion.String(), Kind: "Application", Name: app.Name,
+				UID: app.UID, Controller: ptr.To(true),
+			}}
+
+			err := r.adopt(context.Background(), app, existing, desired)
+			if tt.wantErr {
+				if held := asHeldError(err); held == nil || held.reason != adoptionConflictReason {
+					t.Errorf("got error %v, want an adoption conflict", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			refs := desired.OwnerReferences
+			if len(refs) != tt.wantRefs || metav1.GetControllerOf(desired).UID != app.UID {
+				t.Errorf("got owner references %v", refs)
+			}
+		})
+	}
+}
+
+func TestCreateOrUpdateServiceAdopts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+	app.UID = "app-uid"
+	existing := NewService(app)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+	r := &ApplicationReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	if err := r.createOrUpdateService(ctx, app); asHeldError(err) == nil {
+		t.Fatalf("got error %v, want an adoption conflict", err)
+	}
+	app.Spec.AdoptionPolicy = v1alpha1.AdoptionPolicyIfUnowned
+	if err := r.createOrUpdateService(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	service := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(existing), service); err != nil {
+		t.Fatal(err)
+	}
+	if !metav1.IsControlledBy(service, app) {
+		t.Errorf("got owner references %v", service.OwnerReferences)
+	}
+}
@@ -19,34 +19,127 @@ package apps
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
-	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/imagepolicy"
+	"github.com/yanxinfire/application-management-operator/internal/permissions"
+	"github.com/yanxinfire/application-management-operator/internal/registry"
+	"github.com/yanxinfire/application-management-operator/internal/sharding"
 )
 
 // ApplicationReconciler reconciles an Application object
 type ApplicationReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
-	logger logr.Logger
+
+	// APIReader reads objects which are only watched by metadata, such as the
+	// referenced ConfigMaps and Secrets. The client is used when it is nil.
+	APIReader client.Reader
+
+	// MaxConcurrentReconciles is the maximum number of Applications reconciled
+	// concurrently, controller-runtime's default is used when it is zero.
+	MaxConcurrentReconciles int
+	// RateLimiter limits how frequently requests are processed, controller-runtime's
+	// default is used when it is nil.
+	RateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+	// ContainerPresets are the containers which Applications can add to their
+	// pods by name as init containers or sidecars.
+	ContainerPresets map[string]corev1.Container
+	// TemplateNamespace is the namespace of the template ConfigMaps selected by
+	// Applications.
+	TemplateNamespace string
+	// Shard restricts the controller to the Applications of this replica's
+	// shard, all Applications are reconciled when it is nil.
+	Shard *sharding.Coordinator
+	// ImagePolicy restricts the images of Applications, any image is allowed
+	// when it is nil.
+	ImagePolicy *imagepolicy.Policy
+	// ImageResolver resolves image tags to digests when the image policy asks
+	// for it.
+	ImageResolver registry.Resolver
+	// RegistryCredentials is the registry credential Secret copied into the
+	// namespace of every Application and added to its image pull secrets,
+	// nothing is copied when its name is empty.
+	RegistryCredentials types.NamespacedName
+	// ImageTagLister lists the tags of image repositories for the image
+	// updates of Applications, images are not updated when it is nil.
+	ImageTagLister registry.TagLister
+	// Recorder records events about Applications, events are dropped when it
+	// is nil.
+	Recorder record.EventRecorder
+	// RefuseRoles refuses to bind the roles of Applications. It is set when the
+	// admission webhook, which checks that the author of an Application may
+	// bind its roles, is disabled.
+	RefuseRoles bool
+
+	// configDigests memoizes the digests of the referenced ConfigMaps and
+	// Secrets by namespace, kind and name.
+	configDigests sync.Map
 }
 
 // +kubebuilder:rbac:groups=apps.xinyan.cn,resources=applications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.xinyan.cn,resources=applications/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=apps.xinyan.cn,resources=applications/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list
+// +kubebuilder:rbac:groups=apps,resources=statefulsets;daemonsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;clusterroles,verbs=bind
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// RequiredPermissions returns the permissions the controller needs, they
+// must be kept in sync with the rbac markers above.
+func RequiredPermissions() []permissions.Permission {
+	crud := []string{"get", "list", "watch", "create", "update", "delete"}
+	var required []permissions.Permission
+	for _, p := range [][]permissions.Permission{
+		permissions.Resource(appsv1alpha1.GroupVersion.Group, []string{"applications"}, "get", "list", "watch", "patch"),
+		permissions.Resource(appsv1alpha1.GroupVersion.Group, []string{"applications/status"}, "update"),
+		permissions.Resource("apps", []string{"deployments", "statefulsets", "daemonsets"}, crud...),
+		permissions.Resource("", []string{"services", "serviceaccounts"}, crud...),
+		permissions.Resource("networking.k8s.io", []string{"ingresses", "networkpolicies"}, crud...),
+		permissions.Resource("batch", []string{"jobs", "cronjobs"}, crud...),
+		permissions.Resource("", []string{"configmaps"}, "get", "list", "watch", "create", "delete"),
+		permissions.Resource("", []string{"secrets"}, crud...),
+		permissions.Resource("", []string{"pods"}, "get", "list"),
+		permissions.Resource(rbacv1.GroupName, []string{"rolebindings"}, crud...),
+		permissions.Resource(rbacv1.GroupName, []string{"roles", "clusterroles"}, "bind"),
+		permissions.Resource("", []string{"events"}, "create", "patch"),
+	} {
+		required = append(required, p...)
+	}
+	return required
+}
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -58,66 +151,212 @@ type ApplicationReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.21.0/pkg/reconcile
 func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.logger = logf.FromContext(ctx, "Application", req.NamespacedName)
+	logger := logf.FromContext(ctx, "Application", req.NamespacedName)
+	ctx = logf.IntoContext(ctx, logger)
 
 	app := &appsv1alpha1.Application{}
 	if err := r.Get(ctx, req.NamespacedName, app); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if r.Shard != nil && !r.Shard.Owns(app) {
+		return ctrl.Result{}, nil
+	}
+	// The image is updated first so that the rest of the reconciliation rolls
+	// it out, a registry failure does not hold back the application.
+	imageUpdate, err := r.updateImage(ctx, app)
+	if err != nil {
+		logger.Error(err, "unable to update image")
+		r.recordEvent(app, corev1.EventTypeWarning, "ImageUpdateFailed", err.Error())
+	}
 	appCopy := app.DeepCopy()
+	appCopy.Status.ImageUpdate = imageUpdate
+
+	result, err := r.reconcileApplication(ctx, appCopy)
+	if next := nextImageCheck(appCopy); next > 0 && (result.RequeueAfter == 0 || next < result.RequeueAfter) {
+		result.RequeueAfter = next
+	}
+	state, observeErr := r.observeWorkload(ctx, appCopy)
+	if observeErr != nil {
+		return ctrl.Result{}, observeErr
+	}
+	if statusErr := r.updateStatus(ctx, app, appCopy, state, err); statusErr != nil {
+		if err == nil {
+			return ctrl.Result{}, statusErr
+		}
+		logger.Error(statusErr, "unable to update Application status")
+	}
+	if asHeldError(err) != nil {
+		// Recorded in the status, the application is reconciled again when the
+		// object holding it back changes.
+		return result, nil
+	}
+	return result, err
+}
 
-	if err := r.verifyApplicationMode(appCopy); err != nil {
+func (r *ApplicationReconciler) reconcileApplication(
+	ctx context.Context, app *appsv1alpha1.Application) (ctrl.Result, error) {
+	if err := r.verifyApplicationMode(ctx, app); err != nil {
+		return ctrl.Result{}, err
+	}
+	if app.Spec.Suspend {
+		err := r.suspend(ctx, app)
+		if asHeldError(err) != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	if err := resolveContainerPresets(app, r.ContainerPresets); err != nil {
 		return ctrl.Result{}, err
 	}
+	if err := r.applyImagePolicy(ctx, app); err != nil {
+		if asHeldError(err) != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	if err := r.syncRegistryCredentials(ctx, app); err != nil {
+		if asHeldError(err) != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+
+	if err := r.createOrUpdateServiceAccount(ctx, app); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+
+	if err := r.createOrUpdateNetworkPolicy(ctx, app); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+
+	// Dependencies only hold back the creation of the workload, a running
+	// application is not stopped when a dependency becomes unavailable.
+	if err := r.checkDependencies(ctx, app); err != nil {
+		held := asHeldError(err)
+		if held == nil {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+		}
+		if held.phase != appsv1alpha1.PhaseWaiting {
+			return ctrl.Result{}, err
+		}
+		state, observeErr := r.observeWorkload(ctx, app)
+		if observeErr != nil {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, observeErr
+		}
+		if state == nil {
+			// The dependencies outside the cache send no events, they are polled.
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+		}
+	}
 
-	if err := r.createOrUpdateDeployment(ctx, appCopy); err != nil {
+	if _, err := r.createConfigFiles(ctx, app); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	ctx, err := r.withTemplateSet(ctx, app)
+	if err != nil {
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 	}
+	switch WorkloadKind(app) {
+	case appsv1alpha1.WorkloadKindStatefulSet:
+		if err := r.createOrUpdateHeadlessService(ctx, app); err != nil {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+		}
+		if err := r.createOrUpdateStatefulSet(ctx, app); err != nil {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+		}
+	case appsv1alpha1.WorkloadKindDaemonSet:
+		if err := r.createOrUpdateDaemonSet(ctx, app); err != nil {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+		}
+	default:
+		if err := r.createOrUpdateDeployment(ctx, app); err != nil {
+			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+		}
+	}
 
-	if err := r.createOrUpdateService(ctx, appCopy); err != nil {
+	if err := r.createOrUpdateService(ctx, app); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	if err := r.createOrUpdateCronJobs(ctx, app); err != nil {
 		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 	}
 	if app.Spec.Expose.Mode == "Ingress" {
-		if err := r.createOrUpdateIngress(ctx, appCopy); err != nil {
+		if err := r.createOrUpdateIngress(ctx, app); err != nil {
 			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 		}
 	} else {
-		if err := r.deleteIngress(ctx, appCopy); err != nil {
+		if err := r.deleteIngress(ctx, app); err != nil {
 			return ctrl.Result{RequeueAfter: 30 * time.Second}, err
 		}
 	}
 
+	state, err := r.observeWorkload(ctx, app)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	if err := r.cleanupWorkloads(ctx, app, state); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	if err := r.cleanupConfigFiles(ctx, app, state); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	if err := r.cleanupServiceAccounts(ctx, app, state); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+	if err := r.cleanupHookJobs(ctx, app, state); err != nil {
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
 func (r *ApplicationReconciler) createOrUpdateDeployment(
 	ctx context.Context, app *appsv1alpha1.Application) error {
-	deployment := NewDeployment(app)
-	err := controllerutil.SetControllerReference(app, deployment, r.Scheme)
+	deployment, err := r.desiredDeployment(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = controllerutil.SetControllerReference(app, deployment, r.Scheme)
 	if err != nil {
 		return err
 	}
 	existingDeployment := &appsv1.Deployment{}
-	if err = r.Get(ctx, types.NamespacedName{
+	if err = r.getOwned(ctx, types.NamespacedName{
 		Namespace: app.Namespace,
 		Name:      app.Name,
 	}, existingDeployment); err != nil {
 		if errors.IsNotFound(err) {
-			r.logger.Info("Creating Deployment", "Namespace",
+			if err := r.runPreDeployHooks(ctx, app, ""); err != nil {
+				return err
+			}
+			logf.FromContext(ctx).Info("Creating Deployment", "Namespace",
 				app.Namespace, "Name", app.Name)
 			return r.Create(ctx, deployment)
 		}
 		return err
 	}
+	if err := r.adopt(ctx, app, existingDeployment, deployment); err != nil {
+		return err
+	}
+	if err := r.runPreDeployHooks(ctx, app,
+		existingDeployment.Spec.Template.Spec.Containers[0].Image); err != nil {
+		return err
+	}
 
 	// Utilise --dry-run='client' to update deployment unsetting properties,
 	// so that it could be compared with existing deployment correctly
+	if deployment.Spec.Replicas == nil {
+		deployment.Spec.Replicas = unsetReplicas(existingDeployment, existingDeployment.Spec.Replicas)
+	}
 	err = r.Update(ctx, deployment, client.DryRunAll)
 	if err != nil {
 		return err
 	}
-	if !equality.Semantic.DeepEqual(deployment.Spec, existingDeployment.Spec) {
-		r.logger.Info("Updating Deployment", "Namespace",
+	if !equality.Semantic.DeepEqual(deployment.Spec, existingDeployment.Spec) ||
+		!equality.Semantic.DeepEqual(deployment.Labels, existingDeployment.Labels) ||
+		!equality.Semantic.DeepEqual(deployment.OwnerReferences, existingDeployment.OwnerReferences) ||
+		resumed(existingDeployment) {
+		logf.FromContext(ctx).Info("Updating Deployment", "Namespace",
 			app.Namespace, "Name", app.Name)
 		return r.Update(ctx, deployment)
 	}
@@ -126,30 +365,38 @@ func (r *ApplicationReconciler) createOrUpdateDeployment(
 
 func (r *ApplicationReconciler) createOrUpdateService(
 	ctx context.Context, app *appsv1alpha1.Application) error {
-	service := NewService(app)
-	err := controllerutil.SetControllerReference(app, service, r.Scheme)
+	service, err := r.desiredService(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = controllerutil.SetControllerReference(app, service, r.Scheme)
 	if err != nil {
 		return err
 	}
 	existingService := &corev1.Service{}
-	if err = r.Get(ctx, types.NamespacedName{
+	if err = r.getOwned(ctx, types.NamespacedName{
 		Namespace: app.Namespace,
 		Name:      app.Name,
 	}, existingService); err != nil {
 		if errors.IsNotFound(err) {
-			r.logger.Info("Creating Service", "Namespace",
+			logf.FromContext(ctx).Info("Creating Service", "Namespace",
 				app.Namespace, "Name", app.Name)
 			return r.Create(ctx, service, client.FieldOwner(app.Name))
 		}
 		return err
 	}
+	if err := r.adopt(ctx, app, existingService, service); err != nil {
+		return err
+	}
 
 	err = r.Update(ctx, service, client.DryRunAll)
 	if err != nil {
 		return err
 	}
-	if !equality.Semantic.DeepEqual(service.Spec, existingService.Spec) {
-		r.logger.Info("Updating Service", "Namespace",
+	if !equality.Semantic.DeepEqual(service.Spec, existingService.Spec) ||
+		!equality.Semantic.DeepEqual(service.Labels, existingService.Labels) ||
+		!equality.Semantic.DeepEqual(service.OwnerReferences, existingService.OwnerReferences) {
+		logf.FromContext(ctx).Info("Updating Service", "Namespace",
 			app.Namespace, "Name", app.Name)
 		return r.Update(ctx, service, client.FieldOwner(app.Name))
 	}
@@ -158,31 +405,39 @@ func (r *ApplicationReconciler) createOrUpdateService(
 
 func (r *ApplicationReconciler) createOrUpdateIngress(
 	ctx context.Context, app *appsv1alpha1.Application) error {
-	ingress := NewIngress(app)
-	err := controllerutil.SetControllerReference(app, ingress, r.Scheme)
+	ingress, err := r.desiredIngress(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = controllerutil.SetControllerReference(app, ingress, r.Scheme)
 	if err != nil {
 		return err
 	}
 
 	existingIngress := &networkingv1.Ingress{}
-	if err = r.Get(ctx, types.NamespacedName{
+	if err = r.getOwned(ctx, types.NamespacedName{
 		Namespace: app.Namespace,
 		Name:      app.Name,
 	}, existingIngress); err != nil {
 		if errors.IsNotFound(err) {
-			r.logger.Info("Creating Ingress", "Namespace",
+			logf.FromContext(ctx).Info("Creating Ingress", "Namespace",
 				app.Namespace, "Name", app.Name)
 			return r.Create(ctx, ingress)
 		}
 		return err
 	}
+	if err := r.adopt(ctx, app, existingIngress, ingress); err != nil {
+		return err
+	}
 
 	err = r.Update(ctx, ingress, client.DryRunAll)
 	if err != nil {
 		return err
 	}
-	if !equality.Semantic.DeepEqual(ingress.Spec, existingIngress.Spec) {
-		r.logger.Info("Updating Ingress", "Namespace",
+	if !equality.Semantic.DeepEqual(ingress.Spec, existingIngress.Spec) ||
+		!equality.Semantic.DeepEqual(ingress.Labels, existingIngress.Labels) ||
+		!equality.Semantic.DeepEqual(ingress.OwnerReferences, existingIngress.OwnerReferences) {
+		logf.FromContext(ctx).Info("Updating Ingress", "Namespace",
 			app.Namespace, "Name", app.Name)
 		return r.Update(ctx, ingress)
 	}
@@ -200,12 +455,15 @@ func (r *ApplicationReconciler) deleteIngress(ctx context.Context, app *appsv1al
 		}
 		return err
 	}
-	r.logger.Info("Deleting Ingress", "Namespace",
+	if !metav1.IsControlledBy(ingress, app) {
+		return nil
+	}
+	logf.FromContext(ctx).Info("Deleting Ingress", "Namespace",
 		app.Namespace, "Name", app.Name)
 	return r.Delete(ctx, ingress)
 }
 
-func (r *ApplicationReconciler) verifyApplicationMode(app *appsv1alpha1.Application) error {
+func (r *ApplicationReconciler) verifyApplicationMode(ctx context.Context, app *appsv1alpha1.Application) error {
 	expose := app.Spec.Expose
 	switch expose.Mode {
 	case "Ingress":
@@ -215,7 +473,7 @@ func (r *ApplicationReconciler) verifyApplicationMode(app *appsv1alpha1.Applicat
 		return nil
 	case "NodePort":
 		if expose.NodePort == 0 {
-			r.logger.Info("mode is NodePort and nodePort is not set, " +
+			logf.FromContext(ctx).Info("mode is NodePort and nodePort is not set, " +
 				"nodePort will be a random number between 30000 and 32767")
 		}
 		if expose.NodePort < 30000 || expose.NodePort > 32767 {
@@ -229,11 +487,54 @@ func (r *ApplicationReconciler) verifyApplicationMode(app *appsv1alpha1.Applicat
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&appsv1alpha1.Application{}).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appsv1alpha1.Application{},
+		configRefIndex, indexConfigRefs); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appsv1alpha1.Application{},
+		dependsOnIndex, indexDependsOn); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &appsv1alpha1.Application{},
+		templateIndex, indexTemplate); err != nil {
+		return err
+	}
+	var forOpts []builder.ForOption
+	if r.Shard != nil {
+		forOpts = append(forOpts, builder.WithPredicates(r.Shard.Predicate()))
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&appsv1alpha1.Application{}, forOpts...)
+	if r.Shard != nil {
+		b = b.WatchesRawSource(source.Channel(r.Shard.Events(), &handler.EnqueueRequestForObject{}))
+	}
+	if r.RegistryCredentials.Name != "" {
+		b = b.WatchesMetadata(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findApplicationsForRegistryCredentials))
+	}
+	return b.
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
+		Owns(&appsv1.DaemonSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&networkingv1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&batchv1.Job{}).
+		Owns(&batchv1.CronJob{}).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Watches(&appsv1alpha1.Application{},
+			handler.EnqueueRequestsFromMapFunc(r.findDependents)).
+		WatchesMetadata(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findApplicationsForConfig("ConfigMap"))).
+		WatchesMetadata(&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.findApplicationsForTemplate)).
+		WatchesMetadata(&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.findApplicationsForConfig("Secret"))).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
+			RateLimiter:             r.RateLimiter,
+		}).
 		Named("apps-application").
 		Complete(r)
 }
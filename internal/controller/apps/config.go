@@ -0,0 +1,277 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const (
+	// ConfigChecksumAnnotation is set on the pod template to the checksum of the
+	// referenced ConfigMaps and Secrets, so that changing them rolls the pods.
+	ConfigChecksumAnnotation = "apps.xinyan.cn/config-checksum"
+
+	// configRefIndex indexes Applications by the ConfigMaps and Secrets they reference.
+	configRefIndex = ".spec.configRefs"
+)
+
+// configRef identifies a ConfigMap or a Secret referenced by an Application.
+type configRef struct {
+	kind string
+	name string
+}
+
+func (c configRef) String() string {
+	return c.kind + "/" + c.name
+}
+
+// configRefs returns the ConfigMaps and Secrets referenced by the application,
+// sorted and without duplicates.
+func configRefs(app *v1alpha1.Application) []configRef {
+	var refs []configRef
+	add := func(configMap, secret string) {
+		if configMap != "" {
+			refs = append(refs, configRef{kind: "ConfigMap", name: configMap})
+		}
+		if secret != "" {
+			refs = append(refs, configRef{kind: "Secret", name: secret})
+		}
+	}
+	for _, c := range app.Spec.ConfigFrom {
+		add(c.ConfigMap, c.Secret)
+	}
+	for _, v := range app.Spec.Volumes {
+		add(v.ConfigMap, v.Secret)
+	}
+	slices.SortFunc(refs, func(a, b configRef) int {
+		return cmp.Or(cmp.Compare(a.kind, b.kind), cmp.Compare(a.name, b.name))
+	})
+	return slices.Compact(refs)
+}
+
+// indexConfigRefs is the indexer func of configRefIndex.
+func indexConfigRefs(obj client.Object) []string {
+	app, ok := obj.(*v1alpha1.Application)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, ref := range configRefs(app) {
+		keys = append(keys, ref.String())
+	}
+	return keys
+}
+
+// NewEnvFrom returns the container env sources of the application's configFrom.
+func NewEnvFrom(app *v1alpha1.Application) []corev1.EnvFromSource {
+	var envFrom []corev1.EnvFromSource
+	for _, c := range app.Spec.ConfigFrom {
+		source := corev1.EnvFromSource{Prefix: c.Prefix}
+		if c.ConfigMap != "" {
+			source.ConfigMapRef = &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: c.ConfigMap},
+			}
+		} else {
+			source.SecretRef = &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: c.Secret},
+			}
+		}
+		envFrom = append(envFrom, source)
+	}
+	return envFrom
+}
+
+// NewConfigVolumes returns the pod volumes and container mounts of the
+// application's volumes.
+func NewConfigVolumes(app *v1alpha1.Application) ([]corev1.Volume, []corev1.VolumeMount) {
+	var volumes []corev1.Volume
+	var mounts []corev1.VolumeMount
+	for _, v := range app.Spec.Volumes {
+		volume := corev1.Volume{Name: v.Name}
+		if v.ConfigMap != "" {
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: v.ConfigMap},
+			}
+		} else {
+			volume.Secret = &corev1.SecretVolumeSource{SecretName: v.Secret}
+		}
+		volumes = append(volumes, volume)
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      v.Name,
+			MountPath: v.MountPath,
+			ReadOnly:  true,
+		})
+	}
+	return volumes, mounts
+}
+
+// configChecksum returns the checksum of the content of every ConfigMap and
+// Secret referenced by the application, or an empty string if there are none.
+// Missing objects are part of the checksum as well, so that creating them later
+// rolls the pods.
+func (r *ApplicationReconciler) configChecksum(ctx context.Context, app *v1alpha1.Application) (string, error) {
+	refs := configRefs(app)
+	if len(refs) == 0 {
+		return "", nil
+	}
+	hash := sha256.New()
+	for _, ref := range refs {
+		hash.Write([]byte(ref.String()))
+		hash.Write([]byte{0})
+		sum, err := r.configDigest(ctx, ref, types.NamespacedName{Namespace: app.Namespace, Name: ref.name})
+		if err != nil {
+			return "", err
+		}
+		if sum == nil {
+			logf.FromContext(ctx).Info("Referenced "+ref.kind+" not found", "Namespace", app.Namespace, "Name", ref.name)
+			hash.Write([]byte("<missing>"))
+			continue
+		}
+		hash.Write(sum)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// cachedDigest is the digest of the content of a ConfigMap or Secret at a
+// resourceVersion.
+type cachedDigest struct {
+	resourceVersion string
+	sum             []byte
+}
+
+// configDigest returns the digest of the content of a referenced ConfigMap or
+// Secret, or nil if it does not exist. They are only watched by metadata, the
+// resourceVersion is read from the cache and the content from the API server
+// when the object is missing from the cache or changed since it was last read.
+func (r *ApplicationReconciler) configDigest(ctx context.Context, ref configRef,
+	key types.NamespacedName) ([]byte, error) {
+	id := key.Namespace + "/" + ref.String()
+	// Render has no cache, only a reader.
+	if r.Client != nil {
+		meta := &metav1.PartialObjectMetadata{}
+		meta.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind(ref.kind))
+		if err := r.Get(ctx, key, meta); err == nil {
+			if cached, ok := r.configDigests.Load(id); ok &&
+				cached.(cachedDigest).resourceVersion == meta.ResourceVersion {
+				return cached.(cachedDigest).sum, nil
+			}
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	var obj client.Object
+	var data map[string][]byte
+	var err error
+	switch ref.kind {
+	case "ConfigMap":
+		cm := &corev1.ConfigMap{}
+		obj = cm
+		if err = r.reader().Get(ctx, key, cm); err == nil {
+			data = make(map[string][]byte, len(cm.Data)+len(cm.BinaryData))
+			for k, v := range cm.Data {
+				data[k] = []byte(v)
+			}
+			for k, v := range cm.BinaryData {
+				data[k] = v
+			}
+		}
+	case "Secret":
+		secret := &corev1.Secret{}
+		obj = secret
+		if err = r.reader().Get(ctx, key, secret); err == nil {
+			data = secret.Data
+		}
+	}
+	if errors.IsNotFound(err) {
+		r.configDigests.Delete(id)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		hash.Write([]byte(k))
+		hash.Write([]byte{0})
+		hash.Write(data[k])
+		hash.Write([]byte{0})
+	}
+	sum := hash.Sum(nil)
+	r.configDigests.Store(id, cachedDigest{resourceVersion: obj.GetResourceVersion(), sum: sum})
+	return sum, nil
+}
+
+// reader returns the reader used for objects which are not cached.
+func (r *ApplicationReconciler) reader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// getOwned reads an object of a kind returned by OwnedObjects. The cache may
+// only hold the objects labelled by the operator, those created before the
+// label was introduced are read from the API server until they are updated.
+func (r *ApplicationReconciler) getOwned(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	err := r.Get(ctx, key, obj)
+	if errors.IsNotFound(err) && r.APIReader != nil {
+		return r.APIReader.Get(ctx, key, obj)
+	}
+	return err
+}
+
+// findApplicationsForConfig maps a ConfigMap or Secret to the Applications
+// referencing it.
+func (r *ApplicationReconciler) findApplicationsForConfig(kind string) func(
+	context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		apps := &v1alpha1.ApplicationList{}
+		ref := configRef{kind: kind, name: obj.GetName()}
+		if err := r.List(ctx, apps, client.InNamespace(obj.GetNamespace()),
+			client.MatchingFields{configRefIndex: ref.String()}); err != nil {
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(apps.Items))
+		for _, app := range apps.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: app.Namespace,
+				Name:      app.Name,
+			}})
+		}
+		return requests
+	}
+}
@@ -0,0 +1,60 @@
+package apps
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestConfigChecksum(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_cfg_cr.yaml")
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-test-env", Namespace: "my-test"},
+		Data:       map[string]string{"LOG_LEVEL": "info"},
+	}
+	c := fake.NewClientBuilder().WithObjects(cm).Build()
+	reads := 0
+	r := &ApplicationReconciler{
+		Client: c,
+		APIReader: interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+				opts ...client.GetOption) error {
+				reads++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}),
+	}
+	ctx := context.Background()
+
+	first, err := r.configChecksum(ctx, app)
+	if err != nil || first == "" {
+		t.Fatalf("got checksum %q, error %v", first, err)
+	}
+	readsBefore := reads
+	if again, _ := r.configChecksum(ctx, app); again != first {
+		t.Errorf("checksum is not stable, got %s and %s", first, again)
+	}
+	// Only the three missing objects are read from the API server again.
+	if got := reads - readsBefore; got != 3 {
+		t.Errorf("got %d reads from the API server, want 3", got)
+	}
+
+	cm.Data["LOG_LEVEL"] = "debug"
+	if err := c.Update(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := r.configChecksum(ctx, app); changed == first {
+		t.Errorf("checksum did not change with the ConfigMap content")
+	}
+
+	if got, _ := r.configChecksum(ctx, &v1alpha1.Application{}); got != "" {
+		t.Errorf("got checksum %q without references", got)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"text/template"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const (
+	// ComponentLabel distinguishes the auxiliary objects generated for an Application.
+	ComponentLabel = "apps.xinyan.cn/component"
+
+	configFilesComponent = "config-files"
+	configFilesVolume    = "config-files"
+)
+
+// NewConfigFilesConfigMap returns the immutable ConfigMap holding the
+// application's configFiles, named after the hash of its content, or nil if
+// the application has no configFiles.
+func NewConfigFilesConfigMap(app *v1alpha1.Application) (*corev1.ConfigMap, error) {
+	if len(app.Spec.ConfigFiles) == 0 {
+		return nil, nil
+	}
+	data := map[string]string{}
+	hash := sha256.New()
+	for i, p := range configFilePaths(app) {
+		content := app.Spec.ConfigFiles[p]
+		if app.Spec.ConfigFilesTemplate {
+			tmpl, err := template.New(p).Option("missingkey=error").Parse(content)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template for config file %s: %w", p, err)
+			}
+			buf := new(bytes.Buffer)
+			if err := tmpl.Execute(buf, app); err != nil {
+				return nil, fmt.Errorf("unable to render config file %s: %w", p, err)
+			}
+			content = buf.String()
+		}
+		data[configFileKey(i, p)] = content
+		hash.Write([]byte(p))
+		hash.Write([]byte{0})
+		hash.Write([]byte(content))
+		hash.Write([]byte{0})
+	}
+
+	metaData := NewMetadata(app)
+	metaData.Name = fmt.Sprintf("%s-files-%s", app.Name, hex.EncodeToString(hash.Sum(nil))[:10])
+	metaData.Labels[ComponentLabel] = configFilesComponent
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metaData,
+		Immutable:  ptr.To(true),
+		Data:       data,
+	}, nil
+}
+
+// MountConfigFiles mounts every file of the configFiles ConfigMap into the
+// first container of the pod at its path.
+func MountConfigFiles(app *v1alpha1.Application, podSpec *corev1.PodSpec, cm *corev1.ConfigMap) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: configFilesVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+			},
+		},
+	})
+	container := &podSpec.Containers[0]
+	for i, p := range configFilePaths(app) {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      configFilesVolume,
+			MountPath: p,
+			SubPath:   configFileKey(i, p),
+			ReadOnly:  true,
+		})
+	}
+}
+
+func configFilePaths(app *v1alpha1.Application) []string {
+	paths := make([]string, 0, len(app.Spec.ConfigFiles))
+	for p := range app.Spec.ConfigFiles {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// configFileKey returns the ConfigMap key of a file, paths cannot be used as
+// keys since they contain slashes.
+func configFileKey(i int, p string) string {
+	return fmt.Sprintf("%d-%s", i, path.Base(p))
+}
+
+// createConfigFiles makes sure the configFiles ConfigMap of the application
+// exists. It is immutable, a change of content results in a new ConfigMap.
+func (r *ApplicationReconciler) createConfigFiles(
+	ctx context.Context, app *v1alpha1.Application) (*corev1.ConfigMap, error) {
+	cm, err := NewConfigFilesConfigMap(app)
+	if err != nil || cm == nil {
+		return nil, err
+	}
+	if err = controllerutil.SetControllerReference(app, cm, r.Scheme); err != nil {
+		return nil, err
+	}
+	err = r.reader().Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}, &corev1.ConfigMap{})
+	if errors.IsNotFound(err) {
+		logf.FromContext(ctx).Info("Creating ConfigMap", "Namespace", cm.Namespace, "Name", cm.Name)
+		return cm, r.Create(ctx, cm)
+	}
+	return cm, err
+}
+
+// cleanupConfigFiles deletes the configFiles ConfigMaps of previous versions
+// once every pod of the workload runs with the current one.
+func (r *ApplicationReconciler) cleanupConfigFiles(ctx context.Context,
+	app *v1alpha1.Application, state *workloadState) error {
+	if !state.available() {
+		return nil
+	}
+	current, err := NewConfigFilesConfigMap(app)
+	if err != nil {
+		return err
+	}
+	var currentName string
+	if current != nil {
+		currentName = current.Name
+		if !slices.ContainsFunc(state.template.Spec.Volumes, func(v corev1.Volume) bool {
+			return v.ConfigMap != nil && v.ConfigMap.Name == currentName
+		}) {
+			return nil
+		}
+	}
+	cms := &corev1.ConfigMapList{}
+	if err := r.reader().List(ctx, cms, client.InNamespace(app.Namespace), client.MatchingLabels{
+		"app":          app.Name,
+		ComponentLabel: configFilesComponent,
+	}); err != nil {
+		return err
+	}
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		if cm.Name == currentName || !metav1.IsControlledBy(cm, app) {
+			continue
+		}
+		logf.FromContext(ctx).Info("Deleting ConfigMap", "Namespace", cm.Namespace, "Name", cm.Name)
+		if err := r.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deploymentRolledOut reports whether every replica of the deployment runs
+// the latest pod template.
+func deploymentRolledOut(deployment *appsv1.Deployment) bool {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false
+	}
+	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+	status := deployment.Status
+	return status.UpdatedReplicas == replicas && status.Replicas == replicas &&
+		status.AvailableReplicas == replicas
+}
@@ -0,0 +1,56 @@
+package apps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestNewConfigFilesConfigMap(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+	if cm, err := NewConfigFilesConfigMap(app); cm != nil || err != nil {
+		t.Fatalf("got %v, %v without configFiles", cm, err)
+	}
+
+	app.Spec.ConfigFiles = map[string]string{
+		"/etc/nginx/conf.d/default.conf": "listen {{ .Spec.Port }};",
+		"/etc/motd":                      "hello",
+	}
+	app.Spec.ConfigFilesTemplate = true
+	cm, err := NewConfigFilesConfigMap(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(cm.Name, "my-test-ing-files-") || cm.Immutable == nil || !*cm.Immutable {
+		t.Errorf("got ConfigMap %s, immutable %v", cm.Name, cm.Immutable)
+	}
+	want := map[string]string{
+		"0-motd":         "hello",
+		"1-default.conf": "listen 80;",
+	}
+	for k, v := range want {
+		if cm.Data[k] != v {
+			t.Errorf("got %s=%q, want %q", k, cm.Data[k], v)
+		}
+	}
+
+	deployment := NewDeployment(app)
+	MountConfigFiles(app, &deployment.Spec.Template.Spec, cm)
+	mounts := deployment.Spec.Template.Spec.Containers[0].VolumeMounts
+	if len(mounts) != 2 || mounts[1].MountPath != "/etc/nginx/conf.d/default.conf" ||
+		mounts[1].SubPath != "1-default.conf" {
+		t.Errorf("got mounts %v", mounts)
+	}
+
+	app.Spec.Port = 8080
+	changed, _ := NewConfigFilesConfigMap(app)
+	if changed.Name == cm.Name {
+		t.Errorf("ConfigMap name did not change with its content")
+	}
+
+	app.Spec.ConfigFiles["/etc/motd"] = "{{ .Spec.Unknown }}"
+	if _, err := NewConfigFilesConfigMap(app); err == nil {
+		t.Errorf("expected an error rendering an unknown field")
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// LoadContainerPresets reads the container presets from a YAML file holding a
+// list of containers, each preset is named after its container.
+func LoadContainerPresets(path string) (map[string]corev1.Container, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var containers []corev1.Container
+	if err := yaml.UnmarshalStrict(b, &containers); err != nil {
+		return nil, fmt.Errorf("invalid container presets %s: %w", path, err)
+	}
+	presets := make(map[string]corev1.Container, len(containers))
+	for _, c := range containers {
+		if c.Name == "" {
+			return nil, fmt.Errorf("invalid container presets %s: a container has no name", path)
+		}
+		presets[c.Name] = c
+	}
+	return presets, nil
+}
+
+// resolveContainerPresets replaces the presets referenced by the extra
+// containers of the application with their container spec.
+func resolveContainerPresets(app *v1alpha1.Application, presets map[string]corev1.Container) error {
+	for _, extra := range [][]v1alpha1.ExtraContainer{app.Spec.InitContainers, app.Spec.Sidecars} {
+		for i := range extra {
+			if extra[i].Preset == "" {
+				continue
+			}
+			preset, ok := presets[extra[i].Preset]
+			if !ok {
+				return fmt.Errorf("unknown container preset %q", extra[i].Preset)
+			}
+			extra[i].Container = preset.DeepCopy()
+		}
+	}
+	return nil
+}
+
+// NewExtraContainers returns the containers of the extra containers of the
+// application, presets must have been resolved. Containers without a security
+// context get the one of the application.
+func NewExtraContainers(app *v1alpha1.Application, extra []v1alpha1.ExtraContainer) []corev1.Container {
+	var containers []corev1.Container
+	for _, e := range extra {
+		if e.Container == nil {
+			continue
+		}
+		container := *e.Container.DeepCopy()
+		if container.SecurityContext == nil {
+			container.SecurityContext = NewSecurityContext(app)
+		}
+		containers = append(containers, container)
+	}
+	return containers
+}
@@ -0,0 +1,49 @@
+package apps
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestResolveContainerPresets(t *testing.T) {
+	presets, err := LoadContainerPresets("testdata/container_presets.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newResource[v1alpha1.Application]("testdata/app_sidecar_cr.yaml")
+	if err := resolveContainerPresets(app, presets); err != nil {
+		t.Fatal(err)
+	}
+	got := NewDeployment(app)
+	want := newResource[appsv1.Deployment]("testdata/deploy_sidecar_expect.yaml")
+	if !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	app.Spec.Sidecars = append(app.Spec.Sidecars, v1alpha1.ExtraContainer{Preset: "unknown"})
+	if err := resolveContainerPresets(app, presets); err == nil {
+		t.Errorf("expected an error resolving an unknown preset")
+	}
+}
+
+func TestExtraContainersSecurity(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_sidecar_cr.yaml")
+	app.Spec.Security = &v1alpha1.Security{}
+	app.Spec.Sidecars = nil
+	template := NewPodTemplate(app)
+	for _, c := range template.Spec.InitContainers {
+		if c.SecurityContext == nil || !*c.SecurityContext.ReadOnlyRootFilesystem {
+			t.Errorf("init container %s does not get the restricted security context", c.Name)
+		}
+		if len(c.VolumeMounts) != 1 || c.VolumeMounts[0].MountPath != "/tmp" {
+			t.Errorf("init container %s does not mount /tmp, got %v", c.Name, c.VolumeMounts)
+		}
+	}
+	if len(template.Spec.Volumes) != 1 {
+		t.Errorf("got volumes %v, want a single tmp volume", template.Spec.Volumes)
+	}
+}
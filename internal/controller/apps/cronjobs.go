@@ -0,0 +1,155 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const (
+	cronJobComponent = "cron-job"
+	// cronJobNameMaxLength is the longest name of a CronJob, the controller
+	// appends 11 characters to it to name its Jobs.
+	cronJobNameMaxLength = 52
+)
+
+// CronJobName returns the name of the CronJob of a periodic task. Names longer
+// than cronJobNameMaxLength are made to fit by truncating the application name
+// and appending a hash of it, to keep the CronJobs of applications with a
+// common prefix apart.
+func CronJobName(app *v1alpha1.Application, cronJob v1alpha1.CronJob) string {
+	name := app.Name
+	if maxLength := cronJobNameMaxLength - len(cronJob.Name) - 10; len(name) > maxLength {
+		hash := sha256.Sum256([]byte(app.Name))
+		name = strings.TrimRight(name[:maxLength], "-.") + "-" + hex.EncodeToString(hash[:4])
+	}
+	return fmt.Sprintf("%s-%s", name, cronJob.Name)
+}
+
+// NewCronJob returns the CronJob of a periodic task of the application.
+func NewCronJob(app *v1alpha1.Application, cronJob v1alpha1.CronJob) *batchv1.CronJob {
+	metaData := NewMetadata(app)
+	metaData.Name = CronJobName(app, cronJob)
+	metaData.Labels[ComponentLabel] = cronJobComponent
+	return &batchv1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metaData,
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   cronJob.Schedule,
+			ConcurrencyPolicy:          cronJob.ConcurrencyPolicy,
+			SuccessfulJobsHistoryLimit: cronJob.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cronJob.FailedJobsHistoryLimit,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: NewJobPodTemplate(app, cronJob.Name, cronJobComponent,
+						cronJob.Command, cronJob.Args),
+				},
+			},
+		},
+	}
+}
+
+// createOrUpdateCronJobs makes sure the CronJobs of the application match its
+// spec and deletes those which are no longer part of it.
+func (r *ApplicationReconciler) createOrUpdateCronJobs(
+	ctx context.Context, app *v1alpha1.Application) error {
+	desired := map[string]bool{}
+	for _, c := range app.Spec.CronJobs {
+		cronJob, err := r.desiredCronJob(ctx, app, c)
+		if err != nil {
+			return err
+		}
+		desired[cronJob.Name] = true
+		if err := r.createOrUpdateCronJob(ctx, app, cronJob); err != nil {
+			return err
+		}
+	}
+
+	cronJobs := &batchv1.CronJobList{}
+	if err := r.List(ctx, cronJobs, client.InNamespace(app.Namespace), client.MatchingLabels{
+		"app":          app.Name,
+		ComponentLabel: cronJobComponent,
+	}); err != nil {
+		return err
+	}
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		if desired[cronJob.Name] || !metav1.IsControlledBy(cronJob, app) {
+			continue
+		}
+		logf.FromContext(ctx).Info("Deleting CronJob", "Namespace", cronJob.Namespace, "Name", cronJob.Name)
+		err := r.Delete(ctx, cronJob, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ApplicationReconciler) createOrUpdateCronJob(ctx context.Context,
+	app *v1alpha1.Application, cronJob *batchv1.CronJob) error {
+	err := controllerutil.SetControllerReference(app, cronJob, r.Scheme)
+	if err != nil {
+		return err
+	}
+	existingCronJob := &batchv1.CronJob{}
+	if err = r.getOwned(ctx, types.NamespacedName{
+		Namespace: cronJob.Namespace,
+		Name:      cronJob.Name,
+	}, existingCronJob); err != nil {
+		if errors.IsNotFound(err) {
+			logf.FromContext(ctx).Info("Creating CronJob", "Namespace",
+				cronJob.Namespace, "Name", cronJob.Name)
+			return r.Create(ctx, cronJob)
+		}
+		return err
+	}
+	if err := r.adopt(ctx, app, existingCronJob, cronJob); err != nil {
+		return err
+	}
+
+	err = r.Update(ctx, cronJob, client.DryRunAll)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(cronJob.Spec, existingCronJob.Spec) ||
+		!equality.Semantic.DeepEqual(cronJob.Labels, existingCronJob.Labels) ||
+		!equality.Semantic.DeepEqual(cronJob.OwnerReferences, existingCronJob.OwnerReferences) {
+		logf.FromContext(ctx).Info("Updating CronJob", "Namespace",
+			cronJob.Namespace, "Name", cronJob.Name)
+		return r.Update(ctx, cronJob)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package apps
+
+import (
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestNewCronJob(t *testing.T) {
+	type args struct {
+		app *v1alpha1.Application
+	}
+	tests := []struct {
+		name string
+		args args
+		want *batchv1.CronJob
+	}{
+		{
+			name: "Test CronJob Generation",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_cron_cr.yaml"),
+			},
+			want: newResource[batchv1.CronJob](
+				"testdata/cronjob_expect.yaml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewCronJob(tt.args.app, tt.args.app.Spec.CronJobs[0])
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronJobNameLength(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_cron_cr.yaml")
+	cronJob := app.Spec.CronJobs[0]
+	if name := CronJobName(app, cronJob); name != app.Name+"-"+cronJob.Name {
+		t.Errorf("got name %s for a short application name", name)
+	}
+
+	app.Name = strings.Repeat("a", 60) + ".web"
+	other := app.DeepCopy()
+	other.Name = strings.Repeat("a", 60) + ".api"
+	cronJob.Name = strings.Repeat("c", 30)
+	name := CronJobName(app, cronJob)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 || len(name) > cronJobNameMaxLength {
+		t.Errorf("got name %s of %d characters, want a valid name of at most 52: %v", name, len(name), errs)
+	}
+	if name == CronJobName(other, cronJob) {
+		t.Errorf("got name %s for two applications", name)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// dependsOnIndex indexes Applications by the Applications they depend on.
+const dependsOnIndex = ".spec.dependsOn"
+
+// dependencies returns the Applications the application depends on.
+func dependencies(app *v1alpha1.Application) []types.NamespacedName {
+	deps := make([]types.NamespacedName, 0, len(app.Spec.DependsOn))
+	for _, d := range app.Spec.DependsOn {
+		namespace := d.Namespace
+		if namespace == "" {
+			namespace = app.Namespace
+		}
+		deps = append(deps, types.NamespacedName{Namespace: namespace, Name: d.Name})
+	}
+	return deps
+}
+
+// indexDependsOn is the indexer func of dependsOnIndex.
+func indexDependsOn(obj client.Object) []string {
+	app, ok := obj.(*v1alpha1.Application)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, dep := range dependencies(app) {
+		keys = append(keys, dep.String())
+	}
+	return keys
+}
+
+// checkDependencies returns a heldError if the application depends on
+// itself through a cycle or if one of its dependencies is not Available.
+func (r *ApplicationReconciler) checkDependencies(ctx context.Context, app *v1alpha1.Application) error {
+	if len(app.Spec.DependsOn) == 0 {
+		return nil
+	}
+	self := client.ObjectKeyFromObject(app)
+	cycle, err := r.dependencyCycle(ctx, self, app, []types.NamespacedName{self}, map[types.NamespacedName]bool{})
+	if err != nil {
+		return err
+	}
+	if cycle != nil {
+		names := make([]string, 0, len(cycle))
+		for _, c := range cycle {
+			names = append(names, c.String())
+		}
+		return &heldError{
+			phase:   v1alpha1.PhaseDegraded,
+			reason:  "DependencyCycle",
+			message: "dependency cycle: " + strings.Join(names, " -> "),
+		}
+	}
+
+	var waiting []string
+	for _, dep := range dependencies(app) {
+		depApp := &v1alpha1.Application{}
+		if err := r.getApplication(ctx, dep, depApp); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			waiting = append(waiting, dep.String()+" (not found)")
+			continue
+		}
+		if !meta.IsStatusConditionTrue(depApp.Status.Conditions, v1alpha1.ConditionAvailable) {
+			waiting = append(waiting, dep.String())
+		}
+	}
+	if len(waiting) > 0 {
+		return &heldError{
+			phase:   v1alpha1.PhaseWaiting,
+			reason:  "DependenciesNotAvailable",
+			message: "waiting for " + strings.Join(waiting, ", "),
+		}
+	}
+	return nil
+}
+
+// dependencyCycle walks the dependencies of app depth first and returns the
+// path back to self if there is one. Dependencies which do not exist yet end
+// the walk, they are reported as missing by checkDependencies.
+func (r *ApplicationReconciler) dependencyCycle(ctx context.Context, self types.NamespacedName,
+	app *v1alpha1.Application, path []types.NamespacedName,
+	visited map[types.NamespacedName]bool) ([]types.NamespacedName, error) {
+	for _, dep := range dependencies(app) {
+		if dep == self {
+			return append(path, dep), nil
+		}
+		if visited[dep] {
+			continue
+		}
+		visited[dep] = true
+		depApp := &v1alpha1.Application{}
+		if err := r.getApplication(ctx, dep, depApp); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to get dependency %s: %w", dep, err)
+		}
+		cycle, err := r.dependencyCycle(ctx, self, depApp, append(path, dep), visited)
+		if cycle != nil || err != nil {
+			return cycle, err
+		}
+	}
+	return nil, nil
+}
+
+// getApplication reads a dependency. Applications outside the cache are read
+// from the API server: those not matching the Application selector are not
+// found in the cache, and the cache of the watched namespaces fails to read
+// the other namespaces with an error of its own.
+func (r *ApplicationReconciler) getApplication(ctx context.Context, key types.NamespacedName,
+	app *v1alpha1.Application) error {
+	err := r.Get(ctx, key, app)
+	if err != nil && r.APIReader != nil {
+		return r.APIReader.Get(ctx, key, app)
+	}
+	return err
+}
+
+// findDependents maps an Application to the Applications depending on it.
+func (r *ApplicationReconciler) findDependents(ctx context.Context, obj client.Object) []reconcile.Request {
+	apps := &v1alpha1.ApplicationList{}
+	if err := r.List(ctx, apps, client.MatchingFields{
+		dependsOnIndex: client.ObjectKeyFromObject(obj).String(),
+	}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(apps.Items))
+	for _, app := range apps.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: app.Namespace,
+			Name:      app.Name,
+		}})
+	}
+	return requests
+}
@@ -0,0 +1,126 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func newDependentApplication(name string, available bool, dependsOn ...v1alpha1.Dependency) *v1alpha1.Application {
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "my-test"},
+		Spec:       v1alpha1.ApplicationSpec{DependsOn: dependsOn},
+	}
+	status := metav1.ConditionFalse
+	if available {
+		status = metav1.ConditionTrue
+	}
+	app.Status.Conditions = []metav1.Condition{{Type: v1alpha1.ConditionAvailable, Status: status}}
+	return app
+}
+
+func TestCheckDependencies(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	objs := []client.Object{
+		newDependentApplication("db", true),
+		newDependentApplication("cache", false),
+		newDependentApplication("a", true, v1alpha1.Dependency{Name: "b"}),
+		newDependentApplication("b", true, v1alpha1.Dependency{Name: "c"}),
+		newDependentApplication("c", true, v1alpha1.Dependency{Name: "a"}),
+		newDependentApplication("other", true, v1alpha1.Dependency{Name: "db"}, v1alpha1.Dependency{Name: "b"}),
+	}
+	r := &ApplicationReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(objs...).WithIndex(&v1alpha1.Application{}, dependsOnIndex, indexDependsOn).Build()}
+
+	tests := []struct {
+		name        string
+		app         *v1alpha1.Application
+		wantPhase   string
+		wantMessage string
+	}{
+		{
+			name: "Test Without Dependencies",
+			app:  newDependentApplication("web", false),
+		},
+		{
+			name: "Test Available Dependency",
+			app:  newDependentApplication("web", false, v1alpha1.Dependency{Name: "db"}),
+		},
+		{
+			name: "Test Unavailable Dependencies",
+			app: newDependentApplication("web", false, v1alpha1.Dependency{Name: "db"},
+				v1alpha1.Dependency{Name: "cache"}, v1alpha1.Dependency{Name: "queue", Namespace: "infra"}),
+			wantPhase:   v1alpha1.PhaseWaiting,
+			wantMessage: "waiting for my-test/cache, infra/queue (not found)",
+		},
+		{
+			name:        "Test Dependency Cycle",
+			app:         objs[2].(*v1alpha1.Application),
+			wantPhase:   v1alpha1.PhaseDegraded,
+			wantMessage: "dependency cycle: my-test/a -> my-test/b -> my-test/c -> my-test/a",
+		},
+		{
+			// A cycle which does not lead back to the application is not its own.
+			name: "Test Cycle Of Dependency",
+			app:  objs[5].(*v1alpha1.Application),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.checkDependencies(context.Background(), tt.app)
+			held := asHeldError(err)
+			if tt.wantPhase == "" {
+				if err != nil {
+					t.Errorf("got error %v", err)
+				}
+				return
+			}
+			if held == nil || held.phase != tt.wantPhase || held.message != tt.wantMessage {
+				t.Errorf("got %v, want phase %s and message %q", err, tt.wantPhase, tt.wantMessage)
+			}
+		})
+	}
+
+	requests := r.findDependents(context.Background(), objs[0])
+	if len(requests) != 1 || requests[0].Name != "other" {
+		t.Errorf("got dependents %v of db, want other", requests)
+	}
+}
+
+func TestCheckDependenciesOutsideCache(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	queue := newDependentApplication("queue", true)
+	queue.Namespace = "infra"
+	api := fake.NewClientBuilder().WithScheme(scheme).WithObjects(queue).Build()
+	// The cache only watches the my-test namespace and fails like the
+	// multi-namespace cache of controller-runtime for the others.
+	cached := interceptor.NewClient(api, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+			opts ...client.GetOption) error {
+			if key.Namespace != "my-test" {
+				return fmt.Errorf("unable to get: %v because of unknown namespace for the cache", key)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	})
+	r := &ApplicationReconciler{Client: cached, APIReader: api}
+	app := newDependentApplication("web", false, v1alpha1.Dependency{Name: "queue", Namespace: "infra"})
+	if err := r.checkDependencies(context.Background(), app); err != nil {
+		t.Errorf("got error %v, want the dependency in another namespace to be available", err)
+	}
+	app = newDependentApplication("web", false, v1alpha1.Dependency{Name: "missing", Namespace: "infra"})
+	if held := asHeldError(r.checkDependencies(context.Background(), app)); held == nil ||
+		held.phase != v1alpha1.PhaseWaiting {
+		t.Errorf("got %v, want the missing dependency to be waited for", held)
+	}
+}
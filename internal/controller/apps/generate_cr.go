@@ -1,10 +1,7 @@
 package apps
 
 import (
-	"bytes"
-	"fmt"
 	"maps"
-	"text/template"
 
 	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -12,27 +9,8 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
-func NewResourceFromTemplate[T any](templateName string, app *v1alpha1.Application) *T {
-	tmpl, err := template.ParseFiles(fmt.Sprintf("templates/%s.yaml", templateName))
-	if err != nil {
-		return nil
-	}
-	buf := new(bytes.Buffer)
-	err = tmpl.Execute(buf, app)
-	if err != nil {
-		return nil
-	}
-	obj := new(T)
-	err = yaml.Unmarshal(buf.Bytes(), obj)
-	if err != nil {
-		return nil
-	}
-	return obj
-}
-
 func NewDeployment(app *v1alpha1.Application) *appsv1.Deployment {
 	metaData := NewMetadata(app)
 	deployment := &appsv1.Deployment{
@@ -48,31 +26,80 @@ func NewDeployment(app *v1alpha1.Application) *appsv1.Deployment {
 				},
 			},
 			Replicas: app.Spec.Replicas,
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:   metaData.GetName(),
-					Labels: metaData.GetLabels(),
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
+			Template: NewPodTemplate(app),
+		},
+	}
+	if strategy := app.Spec.UpdateStrategy; strategy != nil {
+		deployment.Spec.Strategy = appsv1.DeploymentStrategy{
+			Type: appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{
+				MaxUnavailable: strategy.MaxUnavailable,
+				MaxSurge:       strategy.MaxSurge,
+			},
+		}
+	}
+	return deployment
+}
+
+func NewPodTemplate(app *v1alpha1.Application) corev1.PodTemplateSpec {
+	metaData := NewMetadata(app)
+	volumes, volumeMounts := NewConfigVolumes(app)
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   metaData.GetName(),
+			Labels: templateLabels(app),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:            app.Name,
+					Image:           app.Spec.Image,
+					ImagePullPolicy: imagePullPolicy(app),
+					Ports: []corev1.ContainerPort{
 						{
-							Name:            app.Name,
-							Image:           app.Spec.Image,
-							ImagePullPolicy: corev1.PullIfNotPresent,
-							Ports: []corev1.ContainerPort{
-								{
-									Name:          "http",
-									ContainerPort: app.Spec.Port,
-									Protocol:      corev1.ProtocolTCP,
-								},
-							},
+							Name:          "http",
+							ContainerPort: app.Spec.Port,
+							Protocol:      corev1.ProtocolTCP,
 						},
 					},
+					Args:            app.Spec.Args,
+					Env:             app.Spec.Env,
+					EnvFrom:         NewEnvFrom(app),
+					VolumeMounts:    volumeMounts,
+					SecurityContext: NewSecurityContext(app),
 				},
 			},
+			Volumes:                   volumes,
+			ImagePullSecrets:          app.Spec.ImagePullSecrets,
+			ServiceAccountName:        ServiceAccountName(app),
+			NodeSelector:              app.Spec.NodeSelector,
+			Tolerations:               app.Spec.Tolerations,
+			Affinity:                  NewAffinity(app),
+			TopologySpreadConstraints: NewTopologySpreadConstraints(app),
+			SecurityContext:           NewPodSecurityContext(app),
 		},
 	}
-	return deployment
+	// The application container stays first, the volumes and mounts added
+	// later on apply to it.
+	template.Spec.InitContainers = NewExtraContainers(app, app.Spec.InitContainers)
+	template.Spec.Containers = append(template.Spec.Containers, NewExtraContainers(app, app.Spec.Sidecars)...)
+	if app.Spec.StartCmd != "" {
+		template.Spec.Containers[0].Command = []string{app.Spec.StartCmd}
+	}
+	if sa := app.Spec.ServiceAccount; sa != nil {
+		template.Spec.AutomountServiceAccountToken = sa.AutomountServiceAccountToken
+	}
+	mountTmp(&template.Spec)
+	return template
+}
+
+// imagePullPolicy returns the pull policy of the application image, images
+// are pulled if not present by default.
+func imagePullPolicy(app *v1alpha1.Application) corev1.PullPolicy {
+	if app.Spec.ImagePullPolicy == "" {
+		return corev1.PullIfNotPresent
+	}
+	return app.Spec.ImagePullPolicy
 }
 
 func NewService(app *v1alpha1.Application) *corev1.Service {
@@ -140,12 +167,22 @@ func NewIngress(app *v1alpha1.Application) *networkingv1.Ingress {
 	return ingress
 }
 
+// templateLabels returns the labels of the pod and volume claim templates of
+// the application. ManagedByLabel is left out, adding it to the templates of
+// existing workloads would roll their pods.
+func templateLabels(app *v1alpha1.Application) map[string]string {
+	labels := NewMetadata(app).Labels
+	delete(labels, ManagedByLabel)
+	return labels
+}
+
 func NewMetadata(app *v1alpha1.Application) metav1.ObjectMeta {
 	labels := map[string]string{
 		"app": app.Name,
 	}
 	delete(app.Labels, "app")
 	maps.Copy(labels, app.Labels)
+	labels[ManagedByLabel] = ManagedByValue
 	return metav1.ObjectMeta{
 		Name:      app.Name,
 		Namespace: app.Namespace,
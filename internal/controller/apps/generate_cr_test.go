@@ -50,8 +50,11 @@ func TestNewResourceFromTemplate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewResourceFromTemplate[appsv1.Deployment](
-				tt.args.templateName, tt.args.app); !reflect.DeepEqual(got, tt.want) {
+			got, err := NewResourceFromTemplate[appsv1.Deployment](tt.args.templateName, tt.args.app)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("got %v, want %v", got, tt.want)
 			}
 		})
@@ -75,6 +78,30 @@ func TestNewDeployment(t *testing.T) {
 			want: newResource[appsv1.Deployment](
 				"testdata/deploy_ing_expect.yaml"),
 		},
+		{
+			name: "Test Deployment With Config Generation",
+			args: args{
+				newResource[v1alpha1.Application](
+					"testdata/app_cfg_cr.yaml")},
+			want: newResource[appsv1.Deployment](
+				"testdata/deploy_cfg_expect.yaml"),
+		},
+		{
+			name: "Test High Availability Deployment Generation",
+			args: args{
+				newResource[v1alpha1.Application](
+					"testdata/app_ha_cr.yaml")},
+			want: newResource[appsv1.Deployment](
+				"testdata/deploy_ha_expect.yaml"),
+		},
+		{
+			name: "Test Restricted Deployment Generation",
+			args: args{
+				newResource[v1alpha1.Application](
+					"testdata/app_sec_cr.yaml")},
+			want: newResource[appsv1.Deployment](
+				"testdata/deploy_sec_expect.yaml"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -172,8 +199,9 @@ func TestNewMetadata(t *testing.T) {
 				Name:      "my-test-ing",
 				Namespace: "my-test",
 				Labels: map[string]string{
-					"app":   "my-test-ing",
-					"owner": "xin_yan",
+					"app":          "my-test-ing",
+					"owner":        "xin_yan",
+					ManagedByLabel: ManagedByValue,
 				},
 			},
 		},
@@ -186,3 +214,22 @@ func TestNewMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPodTemplateImagePullPolicy(t *testing.T) {
+	for policy, want := range map[corev1.PullPolicy]corev1.PullPolicy{
+		"":                corev1.PullIfNotPresent,
+		corev1.PullAlways: corev1.PullAlways,
+		corev1.PullNever:  corev1.PullNever,
+	} {
+		app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+		app.Spec.ImagePullPolicy = policy
+		app.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+		template := NewPodTemplate(app)
+		if got := template.Spec.Containers[0].ImagePullPolicy; got != want {
+			t.Errorf("policy %q: got image pull policy %s, want %s", policy, got, want)
+		}
+		if !reflect.DeepEqual(template.Spec.ImagePullSecrets, app.Spec.ImagePullSecrets) {
+			t.Errorf("got image pull secrets %v", template.Spec.ImagePullSecrets)
+		}
+	}
+}
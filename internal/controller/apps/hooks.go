@@ -0,0 +1,197 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const preDeployHookComponent = "pre-deploy-hook"
+
+// NewJobPodTemplate returns the pod template of a Job of the application, it
+// runs the command with the image, env, config and security settings of the
+// application. The pods keep the app label so that the network policies of the
+// application and of its peers apply to them. Without the http port they never
+// become endpoints of the application's Service.
+func NewJobPodTemplate(app *v1alpha1.Application, name, component string,
+	command, args []string) corev1.PodTemplateSpec {
+	template := NewPodTemplate(app)
+	template.Labels[ComponentLabel] = component
+	template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	template.Spec.Affinity = app.Spec.Affinity
+	template.Spec.TopologySpreadConstraints = nil
+	// Sidecars would keep the Job from completing, native sidecars are
+	// stopped once the application container exits.
+	template.Spec.Containers = template.Spec.Containers[:1]
+	container := &template.Spec.Containers[0]
+	container.Name = name
+	container.Command = command
+	container.Args = args
+	container.Env = append([]corev1.EnvVar{}, app.Spec.Env...)
+	container.Ports = nil
+	return template
+}
+
+// HookJobName returns the name of the Job of a hook for the current image of
+// the application, so that the hook runs once per image. The name is used in
+// the job-name label of the pods and is kept within 63 characters by
+// truncating the application name, the hash then covers the application name
+// as well to keep the Jobs of applications with a common prefix apart.
+func HookJobName(app *v1alpha1.Application, hook v1alpha1.HookJob) string {
+	name, hashed := app.Name, app.Spec.Image
+	if maxLength := validation.DNS1123LabelMaxLength - len(hook.Name) - 10; len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "-.")
+		hashed = app.Name + "/" + app.Spec.Image
+	}
+	hash := sha256.Sum256([]byte(hashed))
+	return fmt.Sprintf("%s-%s-%s", name, hook.Name, hex.EncodeToString(hash[:])[:8])
+}
+
+// NewHookJob returns the Job of a hook.
+func NewHookJob(app *v1alpha1.Application, hook v1alpha1.HookJob) *batchv1.Job {
+	metaData := NewMetadata(app)
+	metaData.Name = HookJobName(app, hook)
+	metaData.Labels[ComponentLabel] = preDeployHookComponent
+
+	template := NewJobPodTemplate(app, hook.Name, preDeployHookComponent, hook.Command, hook.Args)
+	container := &template.Spec.Containers[0]
+	if hook.Image != "" {
+		container.Image = hook.Image
+	}
+	container.Env = append(container.Env, hook.Env...)
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metaData,
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          hook.BackoffLimit,
+			ActiveDeadlineSeconds: hook.ActiveDeadlineSeconds,
+			Template:              template,
+		},
+	}
+}
+
+// runPreDeployHooks runs the preDeploy hooks of the application in order when
+// its image differs from the image of the live workload, an empty string if
+// the workload does not exist yet. It returns a heldError until they all have
+// succeeded.
+func (r *ApplicationReconciler) runPreDeployHooks(ctx context.Context,
+	app *v1alpha1.Application, liveImage string) error {
+	if app.Spec.Hooks == nil || liveImage == app.Spec.Image {
+		return nil
+	}
+	for _, hook := range app.Spec.Hooks.PreDeploy {
+		job, err := r.desiredHookJob(ctx, app, hook)
+		if err != nil {
+			return err
+		}
+		if err := controllerutil.SetControllerReference(app, job, r.Scheme); err != nil {
+			return err
+		}
+		existingJob := &batchv1.Job{}
+		if err := r.getOwned(ctx, types.NamespacedName{Namespace: job.Namespace, Name: job.Name}, existingJob); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+			logf.FromContext(ctx).Info("Creating Job", "Namespace", job.Namespace, "Name", job.Name)
+			if err := r.Create(ctx, job); err != nil {
+				return err
+			}
+			existingJob = job
+		}
+		switch {
+		case jobCondition(existingJob, batchv1.JobComplete):
+			continue
+		case jobCondition(existingJob, batchv1.JobFailed):
+			return &heldError{
+				phase:  v1alpha1.PhaseDegraded,
+				reason: "PreDeployHookFailed",
+				message: fmt.Sprintf("pre-deploy hook %s failed, see kubectl logs -n %s job/%s "+
+					"and delete the Job to retry", hook.Name, job.Namespace, job.Name),
+			}
+		default:
+			return &heldError{
+				phase:   v1alpha1.PhaseProgressing,
+				reason:  "PreDeployHookRunning",
+				message: fmt.Sprintf("waiting for pre-deploy hook %s, Job %s", hook.Name, job.Name),
+			}
+		}
+	}
+	return nil
+}
+
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanupHookJobs deletes the hook Jobs of previous images once the workload
+// runs the current one.
+func (r *ApplicationReconciler) cleanupHookJobs(ctx context.Context,
+	app *v1alpha1.Application, state *workloadState) error {
+	if !state.available() || state.template.Spec.Containers[0].Image != app.Spec.Image {
+		return nil
+	}
+	current := map[string]bool{}
+	if app.Spec.Hooks != nil {
+		for _, hook := range app.Spec.Hooks.PreDeploy {
+			current[HookJobName(app, hook)] = true
+		}
+	}
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(app.Namespace), client.MatchingLabels{
+		"app":          app.Name,
+		ComponentLabel: preDeployHookComponent,
+	}); err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if current[job.Name] || !metav1.IsControlledBy(job, app) {
+			continue
+		}
+		logf.FromContext(ctx).Info("Deleting Job", "Namespace", job.Namespace, "Name", job.Name)
+		err := r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
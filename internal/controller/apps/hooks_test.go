@@ -0,0 +1,111 @@
+package apps
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestNewHookJob(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_hook_cr.yaml")
+	want := newResource[batchv1.Job]("testdata/job_hook_expect.yaml")
+	got := NewHookJob(app, app.Spec.Hooks.PreDeploy[0])
+	if !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	app.Spec.Image = "my-registry/web:1.3.0"
+	if HookJobName(app, app.Spec.Hooks.PreDeploy[0]) == want.Name {
+		t.Errorf("Job name did not change with the image")
+	}
+}
+
+func TestHookJobNameLength(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_hook_cr.yaml")
+	app.Name = strings.Repeat("a", 60) + ".web"
+	other := app.DeepCopy()
+	other.Name = strings.Repeat("a", 60) + ".api"
+	hook := v1alpha1.HookJob{Name: strings.Repeat("m", 30)}
+
+	name := HookJobName(app, hook)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 || len(name) > validation.DNS1123LabelMaxLength {
+		t.Errorf("got name %s of %d characters, want a valid name of at most 63: %v", name, len(name), errs)
+	}
+	if name == HookJobName(other, hook) {
+		t.Errorf("got name %s for two applications", name)
+	}
+}
+
+func TestRunPreDeployHooks(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &ApplicationReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+	app := newResource[v1alpha1.Application]("testdata/app_hook_cr.yaml")
+
+	if err := r.runPreDeployHooks(ctx, app, app.Spec.Image); err != nil {
+		t.Errorf("got %v with an unchanged image", err)
+	}
+
+	setCondition := func(conditionType batchv1.JobConditionType) {
+		job := &batchv1.Job{}
+		key := types.NamespacedName{Namespace: app.Namespace, Name: HookJobName(app, app.Spec.Hooks.PreDeploy[0])}
+		if err := c.Get(ctx, key, job); err != nil {
+			t.Fatal(err)
+		}
+		job.Status.Conditions = []batchv1.JobCondition{{Type: conditionType, Status: corev1.ConditionTrue}}
+		if err := c.Status().Update(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name      string
+		condition batchv1.JobConditionType
+		wantPhase string
+	}{
+		{
+			name:      "Test Running Hook",
+			wantPhase: v1alpha1.PhaseProgressing,
+		},
+		{
+			name:      "Test Failed Hook",
+			condition: batchv1.JobFailed,
+			wantPhase: v1alpha1.PhaseDegraded,
+		},
+		{
+			name:      "Test Completed Hook",
+			condition: batchv1.JobComplete,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.condition != "" {
+				setCondition(tt.condition)
+			}
+			err := r.runPreDeployHooks(ctx, app, "my-registry/web:1.1.0")
+			held := asHeldError(err)
+			if tt.wantPhase == "" {
+				if err != nil {
+					t.Errorf("got error %v", err)
+				}
+				return
+			}
+			if held == nil || held.phase != tt.wantPhase {
+				t.Errorf("got %v, want phase %s", err, tt.wantPhase)
+			}
+		})
+	}
+}
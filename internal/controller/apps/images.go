@@ -0,0 +1,134 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/registry"
+)
+
+const (
+	imagePolicyViolationReason = "ImagePolicyViolation"
+	// registryLookupTimeout bounds a lookup in a registry, including the token
+	// requests and the pages of a tag list, so that an unresponsive registry
+	// does not hold up the reconcile.
+	registryLookupTimeout = time.Minute
+)
+
+// applyImagePolicy holds back applications whose images the image policy
+// does not allow, and pins the image of the application to the digest of its
+// tag when the policy resolves digests. The digest is recorded in the status
+// and only resolved again when the image changes, a tag moved in the registry
+// is not rolled out by itself.
+func (r *ApplicationReconciler) applyImagePolicy(ctx context.Context, app *v1alpha1.Application) error {
+	if errs := r.ImagePolicy.Validate(app); len(errs) > 0 {
+		return &heldError{
+			phase:   v1alpha1.PhaseDegraded,
+			reason:  imagePolicyViolationReason,
+			message: errs.ToAggregate().Error(),
+		}
+	}
+	if r.ImagePolicy == nil || !r.ImagePolicy.ResolveDigests || r.ImageResolver == nil {
+		app.Status.ResolvedImage = ""
+		return nil
+	}
+	resolved, err := r.resolveImage(ctx, app)
+	if err != nil {
+		return err
+	}
+	app.Status.ResolvedImage = resolved
+	if resolved != "" {
+		app.Spec.Image = resolved
+	}
+	return nil
+}
+
+// resolveImage returns the image of the application pinned to a digest, or
+// an empty string if it is already pinned.
+func (r *ApplicationReconciler) resolveImage(ctx context.Context, app *v1alpha1.Application) (string, error) {
+	ref, err := registry.ParseReference(app.Spec.Image)
+	if err != nil {
+		return "", err
+	}
+	if ref.Digest != "" {
+		return "", nil
+	}
+	if strings.HasPrefix(app.Status.ResolvedImage, app.Spec.Image+"@") {
+		return app.Status.ResolvedImage, nil
+	}
+	ctx, cancel, err := r.registryContext(ctx, app)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+	digest, err := r.ImageResolver.Digest(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolving image %s: %w", app.Spec.Image, err)
+	}
+	return app.Spec.Image + "@" + digest, nil
+}
+
+// registryContext returns the context of a registry lookup for the
+// application, bounded by registryLookupTimeout and authenticated with the
+// registry credentials of the operator and the image pull secrets of the
+// application, which take precedence. Secrets that do not exist yet or do not
+// hold registry credentials are skipped.
+func (r *ApplicationReconciler) registryContext(ctx context.Context,
+	app *v1alpha1.Application) (context.Context, context.CancelFunc, error) {
+	keys := make([]types.NamespacedName, 0, len(app.Spec.ImagePullSecrets)+1)
+	if r.RegistryCredentials.Name != "" {
+		keys = append(keys, r.RegistryCredentials)
+	}
+	for _, ref := range app.Spec.ImagePullSecrets {
+		keys = append(keys, types.NamespacedName{Namespace: app.Namespace, Name: ref.Name})
+	}
+	keychain := registry.Keychain{}
+	for _, key := range keys {
+		secret := &corev1.Secret{}
+		if err := r.reader().Get(ctx, key, secret); errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return ctx, nil, fmt.Errorf("unable to read image pull secret %s: %w", key, err)
+		}
+		var data []byte
+		switch secret.Type {
+		case corev1.SecretTypeDockerConfigJson:
+			data = secret.Data[corev1.DockerConfigJsonKey]
+		case corev1.SecretTypeDockercfg:
+			data = secret.Data[corev1.DockerConfigKey]
+		default:
+			continue
+		}
+		credentials, err := registry.ParseDockerConfig(data)
+		if err != nil {
+			return ctx, nil, fmt.Errorf("invalid image pull secret %s: %w", key, err)
+		}
+		maps.Copy(keychain, credentials)
+	}
+	ctx, cancel := context.WithTimeout(registry.WithKeychain(ctx, keychain), registryLookupTimeout)
+	return ctx, cancel, nil
+}
@@ -0,0 +1,126 @@
+package apps
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/imagepolicy"
+	"github.com/yanxinfire/application-management-operator/internal/registry"
+	"github.com/yanxinfire/application-management-operator/internal/registry/registrytest"
+)
+
+func TestApplyImagePolicy(t *testing.T) {
+	reg := registrytest.NewRegistry(true)
+	defer reg.Close()
+	v1 := reg.Push("team/app", "v1")
+	r := &ApplicationReconciler{
+		ImagePolicy:   &imagepolicy.Policy{ForbidLatest: true, ResolveDigests: true},
+		ImageResolver: &registry.Client{Insecure: []string{reg.Host()}},
+	}
+	ctx := context.Background()
+	newApp := func(image string, status v1alpha1.ApplicationStatus) *v1alpha1.Application {
+		return &v1alpha1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+			Spec:       v1alpha1.ApplicationSpec{Image: image},
+			Status:     status,
+		}
+	}
+
+	err := r.applyImagePolicy(ctx, newApp(reg.Host()+"/team/app", v1alpha1.ApplicationStatus{}))
+	if held := asHeldError(err); held == nil || held.reason != imagePolicyViolationReason {
+		t.Fatalf("got error %v for a latest image, want an image policy violation", err)
+	}
+
+	image := reg.Host() + "/team/app:v1"
+	app := newApp(image, v1alpha1.ApplicationStatus{})
+	if err := r.applyImagePolicy(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	if want := image + "@" + v1; app.Spec.Image != want || app.Status.ResolvedImage != want {
+		t.Fatalf("got image %s and resolved image %s, want %s", app.Spec.Image, app.Status.ResolvedImage, want)
+	}
+
+	// The tag is not resolved again until the image changes.
+	reg.Push("team/app", "v1")
+	app = newApp(image, app.Status)
+	if err := r.applyImagePolicy(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	if want := image + "@" + v1; app.Spec.Image != want {
+		t.Errorf("got image %s after the tag moved, want %s", app.Spec.Image, want)
+	}
+	v2 := reg.Push("team/app", "v2")
+	app = newApp(reg.Host()+"/team/app:v2", app.Status)
+	if err := r.applyImagePolicy(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	if want := reg.Host() + "/team/app:v2@" + v2; app.Status.ResolvedImage != want {
+		t.Errorf("got resolved image %s, want %s", app.Status.ResolvedImage, want)
+	}
+
+	r.ImagePolicy.ResolveDigests = false
+	if err := r.applyImagePolicy(ctx, app); err != nil || app.Status.ResolvedImage != "" {
+		t.Errorf("got resolved image %q and error %v without digest resolution", app.Status.ResolvedImage, err)
+	}
+}
+
+type resolverFunc func(ctx context.Context, ref registry.Reference) (string, error)
+
+func (f resolverFunc) Digest(ctx context.Context, ref registry.Reference) (string, error) {
+	return f(ctx, ref)
+}
+
+func TestResolveImageDeadline(t *testing.T) {
+	r := &ApplicationReconciler{
+		ImageResolver: resolverFunc(func(ctx context.Context, ref registry.Reference) (string, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				return "", errors.New("no deadline")
+			}
+			return "sha256:0", nil
+		}),
+	}
+	app := &v1alpha1.Application{Spec: v1alpha1.ApplicationSpec{Image: "team/app:v1"}}
+	if _, err := r.resolveImage(context.Background(), app); err != nil {
+		t.Errorf("got %v, want the lookup bounded by a deadline", err)
+	}
+}
+
+func TestResolveImageWithPullSecret(t *testing.T) {
+	reg := registrytest.NewRegistry(true)
+	defer reg.Close()
+	reg.RequireCredentials("ci", "secret")
+	digest := reg.Push("team/app", "v1")
+	auth := base64.StdEncoding.EncodeToString([]byte("ci:secret"))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: "default"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: fmt.Appendf(nil, `{"auths":{%q:{"auth":%q}}}`, reg.Host(), auth),
+		},
+	}
+	r := &ApplicationReconciler{
+		Client:        fake.NewClientBuilder().WithObjects(secret).Build(),
+		ImageResolver: &registry.Client{Insecure: []string{reg.Host()}},
+	}
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       v1alpha1.ApplicationSpec{Image: reg.Host() + "/team/app:v1"},
+	}
+	ctx := context.Background()
+
+	if _, err := r.resolveImage(ctx, app); err == nil {
+		t.Error("got no error without image pull secrets")
+	}
+	app.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "missing"}, {Name: "pull"}}
+	if got, err := r.resolveImage(ctx, app); err != nil || got != app.Spec.Image+"@"+digest {
+		t.Errorf("got image %s and error %v, want it resolved with the image pull secret", got, err)
+	}
+}
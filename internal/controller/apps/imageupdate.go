@@ -0,0 +1,157 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/registry"
+)
+
+// defaultImageUpdateInterval is how often the tags of the image repository
+// are listed when the application does not set an interval.
+const defaultImageUpdateInterval = 5 * time.Minute
+
+// updateImage lists the tags of the image repository when the image update
+// interval of the application has elapsed, and updates the image of the
+// application to the newest tag matching its policy. The image is only ever
+// updated to a higher version. The returned status records the check and the
+// update, the previous status is kept when the tags could not be listed or
+// the application is suspended.
+func (r *ApplicationReconciler) updateImage(ctx context.Context,
+	app *v1alpha1.Application) (*v1alpha1.ImageUpdateStatus, error) {
+	update := app.Spec.ImageUpdate
+	if update == nil || r.ImageTagLister == nil {
+		return nil, nil
+	}
+	status := app.Status.ImageUpdate.DeepCopy()
+	if status == nil {
+		status = &v1alpha1.ImageUpdateStatus{}
+	}
+	now := metav1.Now()
+	if app.Spec.Suspend ||
+		status.LastCheckTime != nil && now.Sub(status.LastCheckTime.Time) < imageUpdateInterval(app) {
+		return status, nil
+	}
+
+	ref, err := registry.ParseReference(app.Spec.Image)
+	if err != nil {
+		return status, err
+	}
+	// Only the registries the image policy allows are contacted.
+	if err := r.ImagePolicy.CheckRegistry(ref); err != nil {
+		return status, err
+	}
+	lookupCtx, cancel, err := r.registryContext(ctx, app)
+	if err != nil {
+		return status, err
+	}
+	defer cancel()
+	tags, err := r.ImageTagLister.Tags(lookupCtx, ref)
+	if err != nil {
+		return status, fmt.Errorf("listing tags of %s: %w", ref.Name(), err)
+	}
+	status.LastCheckTime = &now
+	tag, err := newestTag(tags, update, ref.Tag)
+	if err != nil || tag == "" {
+		return status, err
+	}
+
+	previous := app.Spec.Image
+	patch := client.MergeFromWithOptions(app.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	app.Spec.Image = imageWithTag(previous, tag)
+	if err := r.Patch(ctx, app, patch); err != nil {
+		app.Spec.Image = previous
+		return status, err
+	}
+	status.LastUpdateTime = &now
+	status.Image = app.Spec.Image
+	status.PreviousImage = previous
+	r.recordEvent(app, corev1.EventTypeNormal, "ImageUpdated",
+		fmt.Sprintf("Updated image from %s to %s", previous, app.Spec.Image))
+	return status, nil
+}
+
+// newestTag returns the highest version among the tags matching the image
+// update policy, or an empty string if none is higher than the current tag.
+// Pre-release versions are only considered with a filter.
+func newestTag(tags []string, update *v1alpha1.ImageUpdate, current string) (string, error) {
+	versions, err := semver.ParseRange(update.Semver)
+	if err != nil {
+		return "", fmt.Errorf("invalid semver range %q: %w", update.Semver, err)
+	}
+	filter, err := regexp.Compile(update.Filter)
+	if err != nil {
+		return "", fmt.Errorf("invalid tag filter %q: %w", update.Filter, err)
+	}
+	newest, _ := semver.ParseTolerant(current)
+	var tag string
+	for _, candidate := range tags {
+		version, err := semver.ParseTolerant(candidate)
+		if err != nil || !filter.MatchString(candidate) || !versions(version) || version.LTE(newest) {
+			continue
+		}
+		if len(version.Pre) > 0 && update.Filter == "" {
+			continue
+		}
+		newest, tag = version, candidate
+	}
+	return tag, nil
+}
+
+// imageWithTag replaces the tag and digest of an image with the tag.
+func imageWithTag(image, tag string) string {
+	name, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	return name + ":" + tag
+}
+
+// imageUpdateInterval returns how often the tags of the image repository of
+// the application are listed.
+func imageUpdateInterval(app *v1alpha1.Application) time.Duration {
+	if interval := app.Spec.ImageUpdate.Interval; interval != nil && interval.Duration > 0 {
+		return interval.Duration
+	}
+	return defaultImageUpdateInterval
+}
+
+// nextImageCheck returns when the tags of the image repository of the
+// application must be listed again, retrying failed checks after 30 seconds.
+// It returns zero when the application has no image update policy or is
+// suspended.
+func nextImageCheck(app *v1alpha1.Application) time.Duration {
+	status := app.Status.ImageUpdate
+	switch {
+	case app.Spec.ImageUpdate == nil || app.Spec.Suspend:
+		return 0
+	case status == nil || status.LastCheckTime == nil:
+		return 30 * time.Second
+	}
+	return max(time.Until(status.LastCheckTime.Add(imageUpdateInterval(app))), 30*time.Second)
+}
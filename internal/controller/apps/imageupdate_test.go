@@ -0,0 +1,132 @@
+package apps
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/imagepolicy"
+	"github.com/yanxinfire/application-management-operator/internal/registry"
+	"github.com/yanxinfire/application-management-operator/internal/registry/registrytest"
+)
+
+func TestNewestTag(t *testing.T) {
+	tags := []string{"latest", "1.3.9", "1.4.0", "v1.4.2", "1.4.10", "1.4.11-rc.1", "1.4.3-alpine", "1.5.0", "nightly"}
+	tests := []struct {
+		name    string
+		update  v1alpha1.ImageUpdate
+		current string
+		want    string
+		wantErr bool
+	}{
+		{name: "patch range", update: v1alpha1.ImageUpdate{Semver: "1.4.x"}, current: "1.4.0", want: "1.4.10"},
+		{name: "up to date", update: v1alpha1.ImageUpdate{Semver: "1.4.x"}, current: "1.4.10"},
+		{name: "never downgrades", update: v1alpha1.ImageUpdate{Semver: "1.4.x"}, current: "1.5.0"},
+		{name: "current not a version", update: v1alpha1.ImageUpdate{Semver: ">=1.0.0 <2.0.0"}, current: "latest",
+			want: "1.5.0"},
+		{name: "filter", update: v1alpha1.ImageUpdate{Semver: "1.4.x", Filter: `-alpine$`}, current: "1.4.0",
+			want: "1.4.3-alpine"},
+		{name: "invalid range", update: v1alpha1.ImageUpdate{Semver: "one"}, wantErr: true},
+		{name: "invalid filter", update: v1alpha1.ImageUpdate{Semver: "1.x", Filter: "("}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newestTag(tags, &tt.update, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newestTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("newestTag() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageWithTag(t *testing.T) {
+	for image, want := range map[string]string{
+		"nginx":                          "nginx:1.5",
+		"localhost:5000/app:1.4":         "localhost:5000/app:1.5",
+		"ghcr.io/org/app:1.4@sha256:abc": "ghcr.io/org/app:1.5",
+	} {
+		if got := imageWithTag(image, "1.5"); got != want {
+			t.Errorf("imageWithTag(%s) = %s, want %s", image, got, want)
+		}
+	}
+}
+
+func TestUpdateImage(t *testing.T) {
+	reg := registrytest.NewRegistry(true)
+	defer reg.Close()
+	for _, tag := range []string{"1.4.0", "1.4.1", "1.5.0"} {
+		reg.Push("team/app", tag)
+	}
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: v1alpha1.ApplicationSpec{
+			Image: reg.Host() + "/team/app:1.4.0",
+			ImageUpdate: &v1alpha1.ImageUpdate{
+				Semver:   "1.4.x",
+				Interval: &metav1.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(app).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &ApplicationReconciler{
+		Client:         c,
+		ImageTagLister: &registry.Client{Insecure: []string{reg.Host()}},
+		Recorder:       recorder,
+	}
+	ctx := context.Background()
+
+	status, err := r.updateImage(ctx, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := reg.Host() + "/team/app:1.4.1"
+	if status.Image != want || status.PreviousImage != reg.Host()+"/team/app:1.4.0" ||
+		status.LastUpdateTime == nil || status.LastCheckTime == nil {
+		t.Errorf("got status %+v after the update to %s", status, want)
+	}
+	live := &v1alpha1.Application{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(app), live); err != nil {
+		t.Fatal(err)
+	}
+	if live.Spec.Image != want {
+		t.Errorf("got image %s, want %s", live.Spec.Image, want)
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "ImageUpdated") {
+		t.Errorf("got event %q, want ImageUpdated", event)
+	}
+
+	// The registry is not listed again before the interval has elapsed.
+	reg.Push("team/app", "1.4.2")
+	live.Status.ImageUpdate = status
+	if status, err = r.updateImage(ctx, live); err != nil || live.Spec.Image != want {
+		t.Errorf("got image %s and error %v before the interval elapsed", live.Spec.Image, err)
+	}
+	if next := nextImageCheck(live); next <= 59*time.Minute {
+		t.Errorf("next check in %s, want about an hour", next)
+	}
+	live.Status.ImageUpdate.LastCheckTime = &metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	if status, err = r.updateImage(ctx, live); err != nil || status.Image != reg.Host()+"/team/app:1.4.2" {
+		t.Errorf("got status %+v and error %v after the interval elapsed", status, err)
+	}
+
+	// Registries the image policy does not allow are not contacted.
+	r.ImagePolicy = &imagepolicy.Policy{AllowedRegistries: []string{"registry.example.com"}}
+	live.Status.ImageUpdate = nil
+	if _, err := r.updateImage(ctx, live); err == nil {
+		t.Error("got no error for a registry the image policy does not allow")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// namespaceNameLabel is set by the API server on every namespace.
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// NewNetworkPolicy returns the NetworkPolicy of the application, or nil if it
+// has no networkPolicy section.
+func NewNetworkPolicy(app *v1alpha1.Application) *networkingv1.NetworkPolicy {
+	policy := app.Spec.NetworkPolicy
+	if policy == nil {
+		return nil
+	}
+	metaData := NewMetadata(app)
+	networkPolicy := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metaData,
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *appSelector(app),
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+
+	var from []networkingv1.NetworkPolicyPeer
+	for _, peer := range policy.From {
+		from = append(from, newNetworkPeer(app, peer.Application, peer.Namespace, peer.CIDR, peer.Except))
+	}
+	if app.Spec.Expose != nil && app.Spec.Expose.Mode == "Ingress" && policy.IngressControllerNamespace != "" {
+		from = append(from, newNetworkPeer(app, "", policy.IngressControllerNamespace, "", nil))
+	}
+	if len(from) > 0 {
+		networkPolicy.Spec.Ingress = append(networkPolicy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From: from,
+			Ports: []networkingv1.NetworkPolicyPort{
+				{
+					Protocol: ptr.To(corev1.ProtocolTCP),
+					Port:     ptr.To(intstr.FromInt32(app.Spec.Port)),
+				},
+			},
+		})
+	}
+	// The pods of a StatefulSet usually form a cluster and talk to each other
+	// on ports other than the application port.
+	if WorkloadKind(app) == v1alpha1.WorkloadKindStatefulSet {
+		networkPolicy.Spec.Ingress = append(networkPolicy.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From: []networkingv1.NetworkPolicyPeer{newNetworkPeer(app, app.Name, "", "", nil)},
+		})
+	}
+
+	if policy.To != nil {
+		networkPolicy.Spec.PolicyTypes = append(networkPolicy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		networkPolicy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{
+			{
+				Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(intstr.FromInt32(53))},
+					{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(53))},
+				},
+			},
+		}
+		for _, rule := range policy.To {
+			networkPolicy.Spec.Egress = append(networkPolicy.Spec.Egress, networkingv1.NetworkPolicyEgressRule{
+				To:    []networkingv1.NetworkPolicyPeer{newNetworkPeer(app, rule.Application, rule.Namespace, rule.CIDR, rule.Except)},
+				Ports: rule.Ports,
+			})
+		}
+	}
+	return networkPolicy
+}
+
+// newNetworkPeer translates a reference to an Application, a namespace or an
+// IP block into a NetworkPolicy peer. Applications are selected by the app
+// label of their pods.
+func newNetworkPeer(app *v1alpha1.Application, application, namespace, cidr string,
+	except []string) networkingv1.NetworkPolicyPeer {
+	if cidr != "" {
+		return networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{CIDR: cidr, Except: except},
+		}
+	}
+	peer := networkingv1.NetworkPolicyPeer{}
+	if application != "" {
+		peer.PodSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": application},
+		}
+	}
+	if namespace != "" && namespace != app.Namespace {
+		peer.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{namespaceNameLabel: namespace},
+		}
+	} else if application == "" {
+		// Every pod of the application namespace.
+		peer.PodSelector = &metav1.LabelSelector{}
+	}
+	return peer
+}
+
+func (r *ApplicationReconciler) createOrUpdateNetworkPolicy(
+	ctx context.Context, app *v1alpha1.Application) error {
+	networkPolicy := NewNetworkPolicy(app)
+	if networkPolicy == nil {
+		return r.deleteNetworkPolicy(ctx, app)
+	}
+	err := controllerutil.SetControllerReference(app, networkPolicy, r.Scheme)
+	if err != nil {
+		return err
+	}
+	existingNetworkPolicy := &networkingv1.NetworkPolicy{}
+	if err = r.getOwned(ctx, types.NamespacedName{
+		Namespace: app.Namespace,
+		Name:      app.Name,
+	}, existingNetworkPolicy); err != nil {
+		if errors.IsNotFound(err) {
+			logf.FromContext(ctx).Info("Creating NetworkPolicy", "Namespace",
+				app.Namespace, "Name", app.Name)
+			return r.Create(ctx, networkPolicy)
+		}
+		return err
+	}
+	if err := r.adopt(ctx, app, existingNetworkPolicy, networkPolicy); err != nil {
+		return err
+	}
+
+	err = r.Update(ctx, networkPolicy, client.DryRunAll)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(networkPolicy.Spec, existingNetworkPolicy.Spec) ||
+		!equality.Semantic.DeepEqual(networkPolicy.Labels, existingNetworkPolicy.Labels) ||
+		!equality.Semantic.DeepEqual(networkPolicy.OwnerReferences, existingNetworkPolicy.OwnerReferences) {
+		logf.FromContext(ctx).Info("Updating NetworkPolicy", "Namespace",
+			app.Namespace, "Name", app.Name)
+		return r.Update(ctx, networkPolicy)
+	}
+	return nil
+}
+
+func (r *ApplicationReconciler) deleteNetworkPolicy(ctx context.Context, app *v1alpha1.Application) error {
+	networkPolicy := &networkingv1.NetworkPolicy{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Namespace: app.Namespace,
+		Name:      app.Name,
+	}, networkPolicy); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(networkPolicy, app) {
+		return nil
+	}
+	logf.FromContext(ctx).Info("Deleting NetworkPolicy", "Namespace",
+		app.Namespace, "Name", app.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, networkPolicy))
+}
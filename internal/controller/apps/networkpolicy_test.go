@@ -0,0 +1,61 @@
+package apps
+
+import (
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestNewNetworkPolicy(t *testing.T) {
+	type args struct {
+		app *v1alpha1.Application
+	}
+	tests := []struct {
+		name string
+		args args
+		want *networkingv1.NetworkPolicy
+	}{
+		{
+			name: "Test NetworkPolicy Generation",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_netpol_cr.yaml"),
+			},
+			want: newResource[networkingv1.NetworkPolicy](
+				"testdata/netpol_expect.yaml"),
+		},
+		{
+			name: "Test Without NetworkPolicy",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_ing_cr.yaml"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewNetworkPolicy(tt.args.app)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNetworkPolicyDenyAll(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_sts_cr.yaml")
+	app.Spec.Expose.Mode = "NodePort"
+	app.Spec.NetworkPolicy = &v1alpha1.NetworkPolicy{}
+	got := NewNetworkPolicy(app)
+	if len(got.Spec.PolicyTypes) != 1 || got.Spec.Egress != nil {
+		t.Errorf("got policy types %v and egress %v without egress rules", got.Spec.PolicyTypes, got.Spec.Egress)
+	}
+	// Only the StatefulSet peers are allowed.
+	if len(got.Spec.Ingress) != 1 || got.Spec.Ingress[0].Ports != nil ||
+		got.Spec.Ingress[0].From[0].PodSelector.MatchLabels["app"] != app.Name {
+		t.Errorf("got ingress %v", got.Spec.Ingress)
+	}
+}
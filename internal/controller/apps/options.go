@@ -0,0 +1,127 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"maps"
+	"time"
+
+	"golang.org/x/time/rate"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const (
+	// ManagedByLabel is set on every object generated by the operator so that
+	// the cache can be restricted to operator-owned objects. It is not set on
+	// pod templates, the pods are not cached.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the value of ManagedByLabel on generated objects.
+	ManagedByValue = "application-management-operator"
+)
+
+// CacheConfig describes which objects the manager cache should hold.
+type CacheConfig struct {
+	// Namespaces restricts the cache to the listed namespaces, all namespaces are
+	// watched when it is empty.
+	Namespaces []string
+	// ConfigNamespaces are cached along with Namespaces for ConfigMaps and
+	// Secrets only, such as the namespace of the template ConfigMaps and of the
+	// registry credentials of the operator.
+	ConfigNamespaces []string
+	// ApplicationSelector restricts the cached Applications to those matching it.
+	ApplicationSelector labels.Selector
+	// OwnedObjectsOnly restricts the cached objects of the kinds returned by
+	// OwnedObjects to those labelled by the operator.
+	OwnedObjectsOnly bool
+}
+
+// CacheOptions converts the config into manager cache options.
+func (c CacheConfig) CacheOptions() cache.Options {
+	opts := cache.Options{}
+	if len(c.Namespaces) > 0 {
+		opts.DefaultNamespaces = map[string]cache.Config{}
+		for _, ns := range c.Namespaces {
+			opts.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+
+	byObject := map[client.Object]cache.ByObject{}
+	if len(opts.DefaultNamespaces) > 0 {
+		namespaces := maps.Clone(opts.DefaultNamespaces)
+		for _, ns := range c.ConfigNamespaces {
+			if ns != "" {
+				namespaces[ns] = cache.Config{}
+			}
+		}
+		if len(namespaces) > len(opts.DefaultNamespaces) {
+			byObject[&corev1.ConfigMap{}] = cache.ByObject{Namespaces: namespaces}
+			byObject[&corev1.Secret{}] = cache.ByObject{Namespaces: namespaces}
+		}
+	}
+	if c.ApplicationSelector != nil && !c.ApplicationSelector.Empty() {
+		byObject[&appsv1alpha1.Application{}] = cache.ByObject{Label: c.ApplicationSelector}
+	}
+	if c.OwnedObjectsOnly {
+		owned := labels.SelectorFromSet(labels.Set{ManagedByLabel: ManagedByValue})
+		for _, obj := range OwnedObjects() {
+			byObject[obj] = cache.ByObject{Label: owned}
+		}
+	}
+	if len(byObject) > 0 {
+		opts.ByObject = byObject
+	}
+	return opts
+}
+
+// OwnedObjects returns an empty instance of every kind the controller owns.
+func OwnedObjects() []client.Object {
+	return []client.Object{
+		&appsv1.Deployment{},
+		&appsv1.StatefulSet{},
+		&appsv1.DaemonSet{},
+		&corev1.Service{},
+		&networkingv1.Ingress{},
+		&networkingv1.NetworkPolicy{},
+		&batchv1.Job{},
+		&batchv1.CronJob{},
+		&corev1.ServiceAccount{},
+		&rbacv1.RoleBinding{},
+	}
+}
+
+// NewRateLimiter returns the default controller rate limiter with the overall
+// bucket limited to the given qps and burst.
+func NewRateLimiter(qps float64, burst int) workqueue.TypedRateLimiter[reconcile.Request] {
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](
+			5*time.Millisecond, 1000*time.Second),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{
+			Limiter: rate.NewLimiter(rate.Limit(qps), burst),
+		},
+	)
+}
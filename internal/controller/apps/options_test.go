@@ -0,0 +1,146 @@
+package apps
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestCacheConfigCacheOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         CacheConfig
+		wantNamespaces []string
+		wantByObject   int
+	}{
+		{
+			name:   "Test Default Cache",
+			config: CacheConfig{},
+		},
+		{
+			name: "Test Namespaced Cache",
+			config: CacheConfig{
+				Namespaces: []string{"team-a", "team-b"},
+			},
+			wantNamespaces: []string{"team-a", "team-b"},
+		},
+		{
+			name: "Test Namespaced Cache With Config Namespaces",
+			config: CacheConfig{
+				Namespaces:       []string{"team-a"},
+				ConfigNamespaces: []string{"system", "team-a"},
+			},
+			wantNamespaces: []string{"team-a"},
+			wantByObject:   2,
+		},
+		{
+			name: "Test Config Namespaces Without Namespaced Cache",
+			config: CacheConfig{
+				ConfigNamespaces: []string{"system"},
+			},
+		},
+		{
+			name: "Test Application Selector",
+			config: CacheConfig{
+				ApplicationSelector: labels.SelectorFromSet(labels.Set{"team": "a"}),
+			},
+			wantByObject: 1,
+		},
+		{
+			name: "Test Owned Objects Only",
+			config: CacheConfig{
+				ApplicationSelector: labels.Everything(),
+				OwnedObjectsOnly:    true,
+			},
+			wantByObject: len(OwnedObjects()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.CacheOptions()
+			if len(got.DefaultNamespaces) != len(tt.wantNamespaces) {
+				t.Errorf("got namespaces %v, want %v", got.DefaultNamespaces, tt.wantNamespaces)
+			}
+			for _, ns := range tt.wantNamespaces {
+				if _, ok := got.DefaultNamespaces[ns]; !ok {
+					t.Errorf("namespace %s is not cached", ns)
+				}
+			}
+			if len(got.ByObject) != tt.wantByObject {
+				t.Errorf("got %d objects, want %d", len(got.ByObject), tt.wantByObject)
+			}
+			for obj, byObject := range got.ByObject {
+				switch obj.(type) {
+				case *v1alpha1.Application:
+					if !byObject.Label.Matches(labels.Set{"team": "a"}) {
+						t.Errorf("application selector %v does not match", byObject.Label)
+					}
+				case *corev1.ConfigMap, *corev1.Secret:
+					if _, ok := byObject.Namespaces["system"]; !ok || len(byObject.Namespaces) != 2 {
+						t.Errorf("got config namespaces %v, want team-a and system", byObject.Namespaces)
+					}
+				case *appsv1.Deployment:
+					if !byObject.Label.Matches(labels.Set{ManagedByLabel: ManagedByValue}) {
+						t.Errorf("owned selector %v does not match", byObject.Label)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOwnedObjectsCacheFallback(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+	app.UID = "app-uid"
+	// The Deployment was created before the operator labelled its objects.
+	existing := NewDeployment(app)
+	delete(existing.Labels, ManagedByLabel)
+	if err := controllerutil.SetControllerReference(app, existing, scheme); err != nil {
+		t.Fatal(err)
+	}
+	api := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+	// The cache only holds the objects labelled by the operator.
+	cached := interceptor.NewClient(api, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+			opts ...client.GetOption) error {
+			if err := c.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			if obj.GetLabels()[ManagedByLabel] != ManagedByValue {
+				return errors.NewNotFound(appsv1.Resource("deployments"), key.Name)
+			}
+			return nil
+		},
+	})
+	r := &ApplicationReconciler{Client: cached, APIReader: api, Scheme: scheme}
+	ctx := context.Background()
+
+	if err := r.createOrUpdateDeployment(ctx, app); err != nil {
+		t.Fatalf("got error %v for an unlabelled Deployment", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := api.Get(ctx, client.ObjectKeyFromObject(existing), deployment); err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Labels[ManagedByLabel] != ManagedByValue {
+		t.Errorf("got labels %v, want the Deployment to be labelled", deployment.Labels)
+	}
+	if _, ok := deployment.Spec.Template.Labels[ManagedByLabel]; ok {
+		t.Errorf("got pod template labels %v, want them unchanged", deployment.Spec.Template.Labels)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const registryCredentialsComponent = "registry-credentials"
+
+// addImagePullSecret adds the Secret to the image pull secrets of the
+// application unless it is already listed.
+func addImagePullSecret(app *v1alpha1.Application, name string) {
+	if name == "" || slices.ContainsFunc(app.Spec.ImagePullSecrets, func(ref corev1.LocalObjectReference) bool {
+		return ref.Name == name
+	}) {
+		return
+	}
+	app.Spec.ImagePullSecrets = append(app.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+}
+
+// syncRegistryCredentials copies the registry credential Secret of the
+// operator into the namespace of the application and adds it to the image
+// pull secrets of the application. The copy is shared by the Applications of
+// the namespace, each of them owns it without controlling it so that it is
+// garbage collected along with the last one.
+func (r *ApplicationReconciler) syncRegistryCredentials(ctx context.Context, app *v1alpha1.Application) error {
+	source := r.RegistryCredentials
+	if source.Name == "" {
+		return nil
+	}
+	addImagePullSecret(app, source.Name)
+	if app.Namespace == source.Namespace {
+		return nil
+	}
+
+	credentials := &corev1.Secret{}
+	if err := r.reader().Get(ctx, source, credentials); err != nil {
+		return fmt.Errorf("unable to read registry credentials %s: %w", source, err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: app.Namespace,
+			Labels: map[string]string{
+				ManagedByLabel: ManagedByValue,
+				ComponentLabel: registryCredentialsComponent,
+			},
+		},
+		Type: credentials.Type,
+		Data: credentials.Data,
+	}
+	existing := &corev1.Secret{}
+	err := r.reader().Get(ctx, client.ObjectKeyFromObject(secret), existing)
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return err
+	case existing.Labels[ComponentLabel] != registryCredentialsComponent:
+		return &heldError{
+			phase:  v1alpha1.PhaseDegraded,
+			reason: adoptionConflictReason,
+			message: fmt.Sprintf("Secret %s already exists and is not the copy of the registry credentials "+
+				"of the operator", existing.Name),
+		}
+	case existing.Type != credentials.Type:
+		// The type of a Secret is immutable.
+		logf.FromContext(ctx).Info("Deleting registry credentials", "Namespace", existing.Namespace, "Name", existing.Name)
+		if err := r.Delete(ctx, existing, client.Preconditions{UID: &existing.UID}); err != nil &&
+			!errors.IsNotFound(err) {
+			return err
+		}
+		secret.OwnerReferences = existing.OwnerReferences
+	default:
+		secret.OwnerReferences = existing.OwnerReferences
+		if err := controllerutil.SetOwnerReference(app, secret, r.Scheme); err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(secret.Data, existing.Data) &&
+			equality.Semantic.DeepEqual(secret.Labels, existing.Labels) &&
+			equality.Semantic.DeepEqual(secret.OwnerReferences, existing.OwnerReferences) {
+			return nil
+		}
+		secret.ResourceVersion = existing.ResourceVersion
+		logf.FromContext(ctx).Info("Updating registry credentials", "Namespace", secret.Namespace, "Name", secret.Name)
+		return r.Update(ctx, secret)
+	}
+
+	if err := controllerutil.SetOwnerReference(app, secret, r.Scheme); err != nil {
+		return err
+	}
+	logf.FromContext(ctx).Info("Creating registry credentials", "Namespace", secret.Namespace, "Name", secret.Name)
+	return r.Create(ctx, secret)
+}
+
+// findApplicationsForRegistryCredentials maps the registry credential Secret
+// of the operator to every Application, and its copies to the Applications of
+// their namespace.
+func (r *ApplicationReconciler) findApplicationsForRegistryCredentials(
+	ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetName() != r.RegistryCredentials.Name {
+		return nil
+	}
+	var opts []client.ListOption
+	if obj.GetNamespace() != r.RegistryCredentials.Namespace {
+		opts = append(opts, client.InNamespace(obj.GetNamespace()))
+	}
+	apps := &v1alpha1.ApplicationList{}
+	if err := r.List(ctx, apps, opts...); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(apps.Items))
+	for _, app := range apps.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: app.Namespace,
+			Name:      app.Name,
+		}})
+	}
+	return requests
+}
@@ -0,0 +1,80 @@
+package apps
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestSyncRegistryCredentials(t *testing.T) {
+	credentials := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "operator-system"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+	}
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(credentials).Build()
+	r := &ApplicationReconciler{
+		Client:              c,
+		Scheme:              scheme,
+		RegistryCredentials: client.ObjectKeyFromObject(credentials),
+	}
+	ctx := context.Background()
+	newApp := func(name string) *v1alpha1.Application {
+		app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+		app.Name, app.UID = name, types.UID(name+"-uid")
+		return app
+	}
+
+	app := newApp("first")
+	if err := r.syncRegistryCredentials(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	template := NewPodTemplate(app)
+	if secrets := template.Spec.ImagePullSecrets; len(secrets) != 1 || secrets[0].Name != "registry" {
+		t.Errorf("got image pull secrets %v, want the registry credentials", secrets)
+	}
+	if policy := template.Spec.Containers[0].ImagePullPolicy; policy != corev1.PullIfNotPresent {
+		t.Errorf("got image pull policy %s, want the IfNotPresent default", policy)
+	}
+
+	credentials.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths":{"registry.example.com":{}}}`)
+	if err := c.Update(ctx, credentials); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.syncRegistryCredentials(ctx, newApp("second")); err != nil {
+		t.Fatal(err)
+	}
+	copied := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: "registry"}, copied); err != nil {
+		t.Fatal(err)
+	}
+	if string(copied.Data[corev1.DockerConfigJsonKey]) != string(credentials.Data[corev1.DockerConfigJsonKey]) {
+		t.Errorf("the copy was not updated with the registry credentials")
+	}
+	if refs := copied.OwnerReferences; len(refs) != 2 || metav1.GetControllerOf(copied) != nil {
+		t.Errorf("got owner references %v, want both applications without a controller", refs)
+	}
+
+	// A Secret of the same name which the operator did not copy is left alone.
+	other := newApp("other")
+	other.Namespace = "other"
+	if err := c.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "other"}}); err != nil {
+		t.Fatal(err)
+	}
+	err := r.syncRegistryCredentials(ctx, other)
+	if held := asHeldError(err); held == nil || held.reason != adoptionConflictReason {
+		t.Errorf("got error %v, want an adoption conflict", err)
+	}
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/overrides"
+)
+
+// RenderOptions are the settings of the controller which change the objects
+// rendered for an application.
+type RenderOptions struct {
+	// ContainerPresets are the containers which Applications can add to their
+	// pods by name as init containers or sidecars.
+	ContainerPresets map[string]corev1.Container
+	// TemplateNamespace is the namespace of the template ConfigMaps selected by
+	// Applications.
+	TemplateNamespace string
+	// RegistryCredentials is the name of the registry credential Secret the
+	// controller copies into the namespace of Applications.
+	RegistryCredentials string
+}
+
+// Render returns the objects the controller creates for the application, in
+// the order it creates them, without changing anything in the cluster. The
+// reader is only used for the template ConfigMaps and the ConfigMaps and
+// Secrets of the config checksum, it may be a fake client. The objects have
+// no owner reference, and the preDeploy hook Jobs are part of them although
+// the controller only runs them when the image changes.
+func Render(ctx context.Context, reader client.Reader, app *v1alpha1.Application,
+	opts RenderOptions) ([]client.Object, error) {
+	r := &ApplicationReconciler{
+		APIReader:         reader,
+		ContainerPresets:  opts.ContainerPresets,
+		TemplateNamespace: opts.TemplateNamespace,
+	}
+	app = app.DeepCopy()
+	if err := r.verifyApplicationMode(ctx, app); err != nil {
+		return nil, err
+	}
+	if err := resolveContainerPresets(app, r.ContainerPresets); err != nil {
+		return nil, err
+	}
+	addImagePullSecret(app, opts.RegistryCredentials)
+	// The registry is not queried, the digest resolved by the controller is
+	// used as long as the image has not changed.
+	if strings.HasPrefix(app.Status.ResolvedImage, app.Spec.Image+"@") {
+		app.Spec.Image = app.Status.ResolvedImage
+	}
+
+	var objects []client.Object
+	if sa := NewServiceAccount(app); sa != nil {
+		objects = append(objects, sa)
+	}
+	for _, binding := range NewRoleBindings(app) {
+		objects = append(objects, binding)
+	}
+	if networkPolicy := NewNetworkPolicy(app); networkPolicy != nil {
+		objects = append(objects, networkPolicy)
+	}
+	configFiles, err := NewConfigFilesConfigMap(app)
+	if err != nil {
+		return nil, err
+	}
+	if configFiles != nil {
+		objects = append(objects, configFiles)
+	}
+
+	ctx, err = r.withTemplateSet(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	var workload client.Object
+	switch WorkloadKind(app) {
+	case v1alpha1.WorkloadKindStatefulSet:
+		objects = append(objects, NewHeadlessService(app))
+		workload, err = r.desiredStatefulSet(ctx, app)
+	case v1alpha1.WorkloadKindDaemonSet:
+		workload, err = r.desiredDaemonSet(ctx, app)
+	default:
+		workload, err = r.desiredDeployment(ctx, app)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if app.Spec.Hooks != nil {
+		for _, hook := range app.Spec.Hooks.PreDeploy {
+			job, err := r.desiredHookJob(ctx, app, hook)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, job)
+		}
+	}
+	objects = append(objects, workload)
+
+	service, err := r.desiredService(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	objects = append(objects, service)
+	for _, c := range app.Spec.CronJobs {
+		cronJob, err := r.desiredCronJob(ctx, app, c)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, cronJob)
+	}
+	if app.Spec.Expose.Mode == "Ingress" {
+		ingress, err := r.desiredIngress(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, ingress)
+	}
+	return objects, nil
+}
+
+// desiredDeployment returns the Deployment of the application with its pod
+// template rendered and its overrides applied.
+func (r *ApplicationReconciler) desiredDeployment(ctx context.Context,
+	app *v1alpha1.Application) (*appsv1.Deployment, error) {
+	deployment, err := r.newDeployment(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.renderPodTemplate(ctx, app, &deployment.Spec.Template); err != nil {
+		return nil, err
+	}
+	return overrides.Apply(deployment, "Deployment", app.Spec.Overrides)
+}
+
+// desiredStatefulSet returns the StatefulSet of the application with its pod
+// template rendered and its overrides applied.
+func (r *ApplicationReconciler) desiredStatefulSet(ctx context.Context,
+	app *v1alpha1.Application) (*appsv1.StatefulSet, error) {
+	statefulSet := NewStatefulSet(app)
+	if err := r.renderPodTemplate(ctx, app, &statefulSet.Spec.Template); err != nil {
+		return nil, err
+	}
+	return overrides.Apply(statefulSet, "StatefulSet", app.Spec.Overrides)
+}
+
+// desiredDaemonSet returns the DaemonSet of the application with its pod
+// template rendered and its overrides applied.
+func (r *ApplicationReconciler) desiredDaemonSet(ctx context.Context,
+	app *v1alpha1.Application) (*appsv1.DaemonSet, error) {
+	daemonSet := NewDaemonSet(app)
+	if err := r.renderPodTemplate(ctx, app, &daemonSet.Spec.Template); err != nil {
+		return nil, err
+	}
+	return overrides.Apply(daemonSet, "DaemonSet", app.Spec.Overrides)
+}
+
+// desiredService returns the Service of the application with its overrides
+// applied.
+func (r *ApplicationReconciler) desiredService(ctx context.Context,
+	app *v1alpha1.Application) (*corev1.Service, error) {
+	service, err := r.newService(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	return overrides.Apply(service, "Service", app.Spec.Overrides)
+}
+
+// desiredIngress returns the Ingress of the application with its overrides
+// applied.
+func (r *ApplicationReconciler) desiredIngress(ctx context.Context,
+	app *v1alpha1.Application) (*networkingv1.Ingress, error) {
+	ingress, err := r.newIngress(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	return overrides.Apply(ingress, "Ingress", app.Spec.Overrides)
+}
+
+// desiredHookJob returns the Job of a preDeploy hook with its pod template
+// rendered.
+func (r *ApplicationReconciler) desiredHookJob(ctx context.Context,
+	app *v1alpha1.Application, hook v1alpha1.HookJob) (*batchv1.Job, error) {
+	job := NewHookJob(app, hook)
+	if err := r.renderPodTemplate(ctx, app, &job.Spec.Template); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// desiredCronJob returns a CronJob of the application with its pod template
+// rendered.
+func (r *ApplicationReconciler) desiredCronJob(ctx context.Context,
+	app *v1alpha1.Application, cronJob v1alpha1.CronJob) (*batchv1.CronJob, error) {
+	desired := NewCronJob(app, cronJob)
+	if err := r.renderPodTemplate(ctx, app, &desired.Spec.JobTemplate.Spec.Template); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
@@ -0,0 +1,100 @@
+package apps
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		app  string
+		want []client.Object
+	}{
+		{
+			name: "Ingress Application",
+			app:  "testdata/app_ing_cr.yaml",
+			want: []client.Object{
+				newResource[appsv1.Deployment]("testdata/deploy_ing_expect.yaml"),
+				newResource[corev1.Service]("testdata/svc_ing_expect.yaml"),
+				newResource[networkingv1.Ingress]("testdata/ing_expect.yaml"),
+			},
+		},
+		{
+			name: "StatefulSet Application",
+			app:  "testdata/app_sts_cr.yaml",
+			want: []client.Object{
+				newResource[corev1.Service]("testdata/svc_headless_expect.yaml"),
+				newResource[appsv1.StatefulSet]("testdata/sts_expect.yaml"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newResource[v1alpha1.Application](tt.app)
+			got, err := Render(context.Background(), fake.NewClientBuilder().Build(), app, RenderOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The goldens are compared with the objects rendered first.
+			if len(got) < len(tt.want) {
+				t.Fatalf("got %d objects, want at least %d", len(got), len(tt.want))
+			}
+			for i := range tt.want {
+				if !equality.Semantic.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("object %d: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderOrder(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_hook_cr.yaml")
+	app.Spec.ServiceAccount = &v1alpha1.ServiceAccount{
+		Roles: []v1alpha1.RoleRef{{Kind: "ClusterRole", Name: "view"}},
+	}
+	app.Spec.ConfigFiles = map[string]string{"/etc/web/web.conf": "listen 8080"}
+	app.Spec.CronJobs = []v1alpha1.CronJob{{Name: "report", Schedule: "0 * * * *"}}
+	secret := &corev1.Secret{}
+	secret.Namespace, secret.Name = "my-test", "my-test-db"
+	reader := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	objects, err := Render(context.Background(), reader, app, RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, obj := range objects {
+		got = append(got, fmt.Sprintf("%s/%s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName()))
+	}
+	want := []string{
+		"ServiceAccount/my-test-hook",
+		"RoleBinding/my-test-hook-clusterrole-view",
+		"ConfigMap/" + objects[2].GetName(),
+		"Job/" + HookJobName(app, app.Spec.Hooks.PreDeploy[0]),
+		"Deployment/my-test-hook",
+		"Service/my-test-hook",
+		"CronJob/my-test-hook-report",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got objects %v, want %v", got, want)
+	}
+	deployment := objects[4].(*appsv1.Deployment)
+	if deployment.Spec.Template.Annotations[ConfigChecksumAnnotation] == "" {
+		t.Errorf("missing config checksum on the pod template")
+	}
+	if app.Spec.ServiceAccount.Name != "" || len(deployment.OwnerReferences) != 0 {
+		t.Errorf("the application was changed or the objects are owned")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// highAvailable reports whether the high availability shorthand applies, a
+// DaemonSet already runs one pod per node.
+func highAvailable(app *v1alpha1.Application) bool {
+	return app.Spec.HighAvailability && WorkloadKind(app) != v1alpha1.WorkloadKindDaemonSet
+}
+
+// NewAffinity returns the affinity of the application's pods, which adds a
+// preferred anti-affinity between the pods on the same node to the declared
+// affinity in high availability mode.
+func NewAffinity(app *v1alpha1.Application) *corev1.Affinity {
+	if !highAvailable(app) {
+		return app.Spec.Affinity
+	}
+	affinity := &corev1.Affinity{}
+	if app.Spec.Affinity != nil {
+		affinity = app.Spec.Affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: appSelector(app),
+				TopologyKey:   corev1.LabelHostname,
+			},
+		})
+	return affinity
+}
+
+// NewTopologySpreadConstraints returns the declared spread constraints, or
+// zone and hostname constraints in high availability mode.
+func NewTopologySpreadConstraints(app *v1alpha1.Application) []corev1.TopologySpreadConstraint {
+	if len(app.Spec.TopologySpreadConstraints) > 0 || !highAvailable(app) {
+		return app.Spec.TopologySpreadConstraints
+	}
+	var constraints []corev1.TopologySpreadConstraint
+	for _, key := range []string{corev1.LabelTopologyZone, corev1.LabelHostname} {
+		constraints = append(constraints, corev1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       key,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     appSelector(app),
+		})
+	}
+	return constraints
+}
+
+func appSelector(app *v1alpha1.Application) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"app": app.Name,
+		},
+	}
+}
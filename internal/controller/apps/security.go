@@ -0,0 +1,117 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const tmpVolume = "tmp"
+
+// restricted reports whether the restricted profile applies to the application.
+func restricted(security *v1alpha1.Security) bool {
+	return security.Profile == "" || security.Profile == v1alpha1.SecurityProfileRestricted
+}
+
+// NewPodSecurityContext returns the pod security context of the application,
+// or nil if it has no security section.
+func NewPodSecurityContext(app *v1alpha1.Application) *corev1.PodSecurityContext {
+	security := app.Spec.Security
+	if security == nil {
+		return nil
+	}
+	podSecurity := &corev1.PodSecurityContext{}
+	if restricted(security) {
+		podSecurity.RunAsNonRoot = ptr.To(true)
+		podSecurity.SeccompProfile = &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		}
+	}
+	if security.RunAsNonRoot != nil {
+		podSecurity.RunAsNonRoot = security.RunAsNonRoot
+	}
+	if security.SeccompProfile != nil {
+		podSecurity.SeccompProfile = security.SeccompProfile
+	}
+	podSecurity.RunAsUser = security.RunAsUser
+	podSecurity.RunAsGroup = security.RunAsGroup
+	podSecurity.FSGroup = security.FSGroup
+	return podSecurity
+}
+
+// NewSecurityContext returns the container security context of the
+// application, or nil if it has no security section.
+func NewSecurityContext(app *v1alpha1.Application) *corev1.SecurityContext {
+	security := app.Spec.Security
+	if security == nil {
+		return nil
+	}
+	containerSecurity := &corev1.SecurityContext{}
+	if restricted(security) {
+		containerSecurity.AllowPrivilegeEscalation = ptr.To(false)
+		containerSecurity.ReadOnlyRootFilesystem = ptr.To(true)
+		containerSecurity.Capabilities = &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		}
+	}
+	if security.AllowPrivilegeEscalation != nil {
+		containerSecurity.AllowPrivilegeEscalation = security.AllowPrivilegeEscalation
+	}
+	if security.ReadOnlyRootFilesystem != nil {
+		containerSecurity.ReadOnlyRootFilesystem = security.ReadOnlyRootFilesystem
+	}
+	if security.Capabilities != nil {
+		containerSecurity.Capabilities = security.Capabilities
+	}
+	containerSecurity.Privileged = security.Privileged
+	return containerSecurity
+}
+
+// mountTmp mounts a writable emptyDir at /tmp in the containers whose root
+// filesystem is read-only.
+func mountTmp(podSpec *corev1.PodSpec) {
+	mounted := false
+	for _, containers := range [][]corev1.Container{podSpec.Containers, podSpec.InitContainers} {
+		for i := range containers {
+			container := &containers[i]
+			if container.SecurityContext == nil || !ptr.Deref(container.SecurityContext.ReadOnlyRootFilesystem, false) ||
+				slices.ContainsFunc(container.VolumeMounts, func(m corev1.VolumeMount) bool {
+					return m.MountPath == "/tmp"
+				}) {
+				continue
+			}
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      tmpVolume,
+				MountPath: "/tmp",
+			})
+			mounted = true
+		}
+	}
+	if mounted && !slices.ContainsFunc(podSpec.Volumes, func(v corev1.Volume) bool { return v.Name == tmpVolume }) {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: tmpVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+}
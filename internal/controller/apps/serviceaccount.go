@@ -0,0 +1,287 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const (
+	serviceAccountComponent = "service-account"
+	rolesRefusedReason      = "RolesRefused"
+)
+
+// invalidNameChars matches the characters of role names which are not allowed
+// in the names of RoleBindings generated from them.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ServiceAccountName returns the name of the ServiceAccount the application's
+// pods run as, or an empty string for the namespace default ServiceAccount.
+func ServiceAccountName(app *v1alpha1.Application) string {
+	sa := app.Spec.ServiceAccount
+	if sa == nil {
+		return ""
+	}
+	if sa.Name != "" {
+		return sa.Name
+	}
+	return app.Name
+}
+
+// createServiceAccount reports whether the operator manages the
+// ServiceAccount of the application.
+func createServiceAccount(app *v1alpha1.Application) bool {
+	return app.Spec.ServiceAccount != nil && ptr.Deref(app.Spec.ServiceAccount.Create, true)
+}
+
+// NewServiceAccount returns the ServiceAccount of the application, or nil if
+// the operator does not manage it.
+func NewServiceAccount(app *v1alpha1.Application) *corev1.ServiceAccount {
+	if !createServiceAccount(app) {
+		return nil
+	}
+	metaData := NewMetadata(app)
+	metaData.Name = ServiceAccountName(app)
+	metaData.Labels[ComponentLabel] = serviceAccountComponent
+	metaData.Annotations = app.Spec.ServiceAccount.Annotations
+	return &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ServiceAccount",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metaData,
+	}
+}
+
+// NewRoleBindings returns a RoleBinding in the application namespace for every
+// role bound to the ServiceAccount of the application.
+func NewRoleBindings(app *v1alpha1.Application) []*rbacv1.RoleBinding {
+	if !createServiceAccount(app) {
+		return nil
+	}
+	bindings := make([]*rbacv1.RoleBinding, 0, len(app.Spec.ServiceAccount.Roles))
+	for _, role := range app.Spec.ServiceAccount.Roles {
+		metaData := NewMetadata(app)
+		metaData.Name = roleBindingName(app, role)
+		metaData.Labels[ComponentLabel] = serviceAccountComponent
+		bindings = append(bindings, &rbacv1.RoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "RoleBinding",
+				APIVersion: "rbac.authorization.k8s.io/v1",
+			},
+			ObjectMeta: metaData,
+			Subjects: []rbacv1.Subject{
+				{
+					Kind:      rbacv1.ServiceAccountKind,
+					Name:      ServiceAccountName(app),
+					Namespace: app.Namespace,
+				},
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     role.Kind,
+				Name:     role.Name,
+			},
+		})
+	}
+	return bindings
+}
+
+// roleBindingName returns the name of the RoleBinding of a role. Role names
+// such as system:aggregate-to-view are not valid object names, they are
+// lowercased, their other characters replaced and a hash of the role appended
+// so that the names stay distinct.
+func roleBindingName(app *v1alpha1.Application, role v1alpha1.RoleRef) string {
+	name := fmt.Sprintf("%s-%s-%s", app.Name, strings.ToLower(role.Kind), role.Name)
+	if len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+	hash := sha256.Sum256([]byte(role.Kind + "/" + role.Name))
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.TrimRight(name[:min(len(name), validation.DNS1123SubdomainMaxLength-9)], "-")
+	return name + "-" + hex.EncodeToString(hash[:4])
+}
+
+// createOrUpdateServiceAccount makes sure the ServiceAccount of the application
+// and its RoleBindings match the spec, and deletes the RoleBindings no longer
+// needed.
+func (r *ApplicationReconciler) createOrUpdateServiceAccount(
+	ctx context.Context, app *v1alpha1.Application) error {
+	if r.RefuseRoles && app.Spec.ServiceAccount != nil && len(app.Spec.ServiceAccount.Roles) > 0 {
+		return &heldError{
+			phase:  v1alpha1.PhaseDegraded,
+			reason: rolesRefusedReason,
+			message: "roles are not bound while the admission webhook is disabled, " +
+				"it checks that the author of the application may bind them",
+		}
+	}
+	sa := NewServiceAccount(app)
+	if sa != nil {
+		if err := controllerutil.SetControllerReference(app, sa, r.Scheme); err != nil {
+			return err
+		}
+		existingSA := &corev1.ServiceAccount{}
+		err := r.getOwned(ctx, types.NamespacedName{Namespace: sa.Namespace, Name: sa.Name}, existingSA)
+		switch {
+		case errors.IsNotFound(err):
+			logf.FromContext(ctx).Info("Creating ServiceAccount", "Namespace", sa.Namespace, "Name", sa.Name)
+			if err := r.Create(ctx, sa); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if err := r.adopt(ctx, app, existingSA, sa); err != nil {
+				return err
+			}
+			if !equality.Semantic.DeepEqual(sa.Labels, existingSA.Labels) ||
+				!equality.Semantic.DeepEqual(sa.Annotations, existingSA.Annotations) ||
+				!equality.Semantic.DeepEqual(sa.OwnerReferences, existingSA.OwnerReferences) {
+				// Only the metadata is managed, the secrets and image pull secrets
+				// of the ServiceAccount are preserved.
+				existingSA.Labels = sa.Labels
+				existingSA.Annotations = sa.Annotations
+				existingSA.OwnerReferences = sa.OwnerReferences
+				logf.FromContext(ctx).Info("Updating ServiceAccount", "Namespace", sa.Namespace, "Name", sa.Name)
+				if err := r.Update(ctx, existingSA); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	desired := map[string]bool{}
+	for _, binding := range NewRoleBindings(app) {
+		desired[binding.Name] = true
+		if err := r.createOrUpdateRoleBinding(ctx, app, binding); err != nil {
+			return err
+		}
+	}
+	return r.cleanupRoleBindings(ctx, app, desired)
+}
+
+func (r *ApplicationReconciler) createOrUpdateRoleBinding(ctx context.Context,
+	app *v1alpha1.Application, binding *rbacv1.RoleBinding) error {
+	if err := controllerutil.SetControllerReference(app, binding, r.Scheme); err != nil {
+		return err
+	}
+	existingBinding := &rbacv1.RoleBinding{}
+	if err := r.getOwned(ctx, types.NamespacedName{
+		Namespace: binding.Namespace,
+		Name:      binding.Name,
+	}, existingBinding); err != nil {
+		if errors.IsNotFound(err) {
+			logf.FromContext(ctx).Info("Creating RoleBinding", "Namespace", binding.Namespace, "Name", binding.Name)
+			return r.Create(ctx, binding)
+		}
+		return err
+	}
+	if err := r.adopt(ctx, app, existingBinding, binding); err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(binding.RoleRef, existingBinding.RoleRef) {
+		// The role of a binding is immutable.
+		logf.FromContext(ctx).Info("Deleting RoleBinding", "Namespace", binding.Namespace, "Name", binding.Name)
+		if err := r.Delete(ctx, existingBinding); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		logf.FromContext(ctx).Info("Creating RoleBinding", "Namespace", binding.Namespace, "Name", binding.Name)
+		return r.Create(ctx, binding)
+	}
+	if !equality.Semantic.DeepEqual(binding.Subjects, existingBinding.Subjects) ||
+		!equality.Semantic.DeepEqual(binding.Labels, existingBinding.Labels) ||
+		!equality.Semantic.DeepEqual(binding.OwnerReferences, existingBinding.OwnerReferences) {
+		logf.FromContext(ctx).Info("Updating RoleBinding", "Namespace", binding.Namespace, "Name", binding.Name)
+		return r.Update(ctx, binding)
+	}
+	return nil
+}
+
+// cleanupRoleBindings deletes the RoleBindings owned by the application which
+// are no longer part of its spec.
+func (r *ApplicationReconciler) cleanupRoleBindings(ctx context.Context,
+	app *v1alpha1.Application, bindings map[string]bool) error {
+	existingBindings := &rbacv1.RoleBindingList{}
+	if err := r.List(ctx, existingBindings, client.InNamespace(app.Namespace), client.MatchingLabels{
+		"app":          app.Name,
+		ComponentLabel: serviceAccountComponent,
+	}); err != nil {
+		return err
+	}
+	for i := range existingBindings.Items {
+		binding := &existingBindings.Items[i]
+		if bindings[binding.Name] || !metav1.IsControlledBy(binding, app) {
+			continue
+		}
+		logf.FromContext(ctx).Info("Deleting RoleBinding", "Namespace", binding.Namespace, "Name", binding.Name)
+		if err := r.Delete(ctx, binding); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cleanupServiceAccounts deletes the ServiceAccounts owned by the application
+// which are no longer part of its spec, once no pod of the workload runs as
+// them anymore.
+func (r *ApplicationReconciler) cleanupServiceAccounts(ctx context.Context,
+	app *v1alpha1.Application, state *workloadState) error {
+	if !state.available() || state.template.Spec.ServiceAccountName != ServiceAccountName(app) {
+		return nil
+	}
+	var current string
+	if createServiceAccount(app) {
+		current = ServiceAccountName(app)
+	}
+	existingSAs := &corev1.ServiceAccountList{}
+	if err := r.List(ctx, existingSAs, client.InNamespace(app.Namespace), client.MatchingLabels{
+		"app":          app.Name,
+		ComponentLabel: serviceAccountComponent,
+	}); err != nil {
+		return err
+	}
+	for i := range existingSAs.Items {
+		sa := &existingSAs.Items[i]
+		if sa.Name == current || !metav1.IsControlledBy(sa, app) {
+			continue
+		}
+		logf.FromContext(ctx).Info("Deleting ServiceAccount", "Namespace", sa.Namespace, "Name", sa.Name)
+		if err := r.Delete(ctx, sa); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package apps
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestNewServiceAccount(t *testing.T) {
+	type args struct {
+		app *v1alpha1.Application
+	}
+	tests := []struct {
+		name string
+		args args
+		want *corev1.ServiceAccount
+	}{
+		{
+			name: "Test ServiceAccount Generation",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_sa_cr.yaml"),
+			},
+			want: newResource[corev1.ServiceAccount](
+				"testdata/sa_expect.yaml"),
+		},
+		{
+			name: "Test Without ServiceAccount",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_ing_cr.yaml"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewServiceAccount(tt.args.app)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRoleBindings(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_sa_cr.yaml")
+	bindings := NewRoleBindings(app)
+	if len(bindings) != 2 {
+		t.Fatalf("got %d RoleBindings, want 2", len(bindings))
+	}
+	want := []struct{ name, kind string }{
+		{"my-test-sa-role-config-reader", "Role"},
+		{"my-test-sa-clusterrole-view", "ClusterRole"},
+	}
+	for i, w := range want {
+		binding := bindings[i]
+		if binding.Name != w.name || binding.RoleRef.Kind != w.kind {
+			t.Errorf("got RoleBinding %s to %s, want %s to %s",
+				binding.Name, binding.RoleRef.Kind, w.name, w.kind)
+		}
+		subject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "my-test-sa", Namespace: "my-test"}
+		if len(binding.Subjects) != 1 || binding.Subjects[0] != subject {
+			t.Errorf("got subjects %v, want %v", binding.Subjects, subject)
+		}
+	}
+
+	template := NewPodTemplate(app)
+	if template.Spec.ServiceAccountName != "my-test-sa" ||
+		!equality.Semantic.DeepEqual(template.Spec.AutomountServiceAccountToken, ptr.To(false)) {
+		t.Errorf("got serviceAccountName %s, automountServiceAccountToken %v",
+			template.Spec.ServiceAccountName, template.Spec.AutomountServiceAccountToken)
+	}
+
+	app.Spec.ServiceAccount.Name = "existing"
+	app.Spec.ServiceAccount.Create = ptr.To(false)
+	if sa, bindings := NewServiceAccount(app), NewRoleBindings(app); sa != nil || bindings != nil {
+		t.Errorf("got %v, %v for an existing ServiceAccount", sa, bindings)
+	}
+	if name := NewPodTemplate(app).Spec.ServiceAccountName; name != "existing" {
+		t.Errorf("got serviceAccountName %s, want existing", name)
+	}
+}
+
+func TestRoleBindingName(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_sa_cr.yaml")
+	names := map[string]bool{}
+	for _, role := range []v1alpha1.RoleRef{
+		{Kind: "ClusterRole", Name: "view"},
+		{Kind: "ClusterRole", Name: "system:aggregate-to-view"},
+		{Kind: "ClusterRole", Name: "system-aggregate-to-view"},
+		{Kind: "ClusterRole", Name: "System:Aggregate-To-View"},
+		{Kind: "Role", Name: strings.Repeat("reader.", 40)},
+	} {
+		name := roleBindingName(app, role)
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			t.Errorf("got invalid name %s for role %s: %v", name, role.Name, errs)
+		}
+		if names[name] {
+			t.Errorf("got name %s for role %s twice", name, role.Name)
+		}
+		names[name] = true
+	}
+	if name := roleBindingName(app, v1alpha1.RoleRef{Kind: "ClusterRole", Name: "view"}); name != "my-test-sa-clusterrole-view" {
+		t.Errorf("got name %s for a valid role name, want it unchanged", name)
+	}
+}
+
+func TestRefuseRoles(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &ApplicationReconciler{Client: c, Scheme: scheme, RefuseRoles: true}
+	app := newResource[v1alpha1.Application]("testdata/app_sa_cr.yaml")
+
+	err := r.createOrUpdateServiceAccount(context.Background(), app)
+	if held := asHeldError(err); held == nil || held.reason != rolesRefusedReason {
+		t.Fatalf("got error %v, want the roles to be refused", err)
+	}
+	bindings := &rbacv1.RoleBindingList{}
+	if err := c.List(context.Background(), bindings, client.InNamespace(app.Namespace)); err != nil {
+		t.Fatal(err)
+	}
+	if len(bindings.Items) > 0 {
+		t.Errorf("got RoleBindings %v, want none", bindings.Items)
+	}
+
+	app.Spec.ServiceAccount.Roles = nil
+	if err := r.createOrUpdateServiceAccount(context.Background(), app); err != nil {
+		t.Errorf("got error %v for a ServiceAccount without roles", err)
+	}
+}
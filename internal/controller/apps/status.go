@@ -0,0 +1,148 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// heldError reports that the rollout of an application is held back, by its
+// dependencies, by a pre-deploy hook, by a resource it may not adopt or by its
+// suspension. It is recorded in the status of the application instead of
+// being retried, the application is reconciled again when the object holding
+// it back changes, or periodically for resources it does not own.
+type heldError struct {
+	phase   string
+	reason  string
+	message string
+}
+
+func (e *heldError) Error() string {
+	return e.message
+}
+
+// asHeldError returns err as a heldError, or nil if it is another error.
+func asHeldError(err error) *heldError {
+	held, _ := err.(*heldError)
+	return held
+}
+
+// updateStatus records the state of the workload and the result of the
+// reconciliation in the status of the application, along with the status
+// fields set on the reconciled copy of the application.
+func (r *ApplicationReconciler) updateStatus(ctx context.Context,
+	app, reconciled *v1alpha1.Application, state *workloadState, reconcileErr error) error {
+	status := app.Status.DeepCopy()
+	status.ResolvedImage = reconciled.Status.ResolvedImage
+	status.ImageUpdate = reconciled.Status.ImageUpdate
+	status.WorkloadKind = WorkloadKind(app)
+	status.Replicas, status.ReadyReplicas, status.UpdatedReplicas, status.Ordinals = 0, 0, 0, nil
+	if state != nil {
+		status.Replicas = state.replicas
+		status.ReadyReplicas = state.ready
+		status.UpdatedReplicas = state.updated
+		status.Ordinals = state.ordinals
+	}
+
+	switch held := asHeldError(reconcileErr); {
+	case held != nil:
+		setPhase(status, app, held.phase, held.reason, held.message)
+	case reconcileErr != nil:
+		setPhase(status, app, v1alpha1.PhaseDegraded, "ReconcileFailed", reconcileErr.Error())
+	case state == nil:
+		setPhase(status, app, v1alpha1.PhasePending, "WorkloadNotFound",
+			fmt.Sprintf("%s has not been created yet", status.WorkloadKind))
+	case state.available():
+		setPhase(status, app, v1alpha1.PhaseAvailable, "WorkloadAvailable",
+			fmt.Sprintf("%d/%d replicas are ready", state.ready, state.replicas))
+	default:
+		setPhase(status, app, v1alpha1.PhaseProgressing, "WorkloadProgressing",
+			fmt.Sprintf("%d/%d replicas are ready", state.ready, state.replicas))
+	}
+
+	var tmplErr *templateError
+	switch {
+	case app.Spec.Template == "":
+		meta.RemoveStatusCondition(&status.Conditions, v1alpha1.ConditionTemplateRendered)
+	case errors.As(reconcileErr, &tmplErr):
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1alpha1.ConditionTemplateRendered,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: app.Generation,
+			Reason:             "RenderFailed",
+			Message:            tmplErr.Error(),
+		})
+	case reconcileErr == nil:
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               v1alpha1.ConditionTemplateRendered,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: app.Generation,
+			Reason:             "Rendered",
+			Message:            fmt.Sprintf("template %s rendered", app.Spec.Template),
+		})
+	}
+
+	if equality.Semantic.DeepEqual(status, &app.Status) {
+		return nil
+	}
+	app.Status = *status
+	return r.Status().Update(ctx, app)
+}
+
+// recordEvent records an event about the application, events are dropped
+// when the reconciler has no recorder.
+func (r *ApplicationReconciler) recordEvent(app *v1alpha1.Application, eventType, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(app, eventType, reason, message)
+	}
+}
+
+// setPhase sets the phase of the application along with the matching
+// standard conditions.
+func setPhase(status *v1alpha1.ApplicationStatus, app *v1alpha1.Application,
+	phase, reason, message string) {
+	status.Phase = phase
+	status.Reason = reason
+	status.Message = message
+	conditionStatus := func(conditionPhase string) metav1.ConditionStatus {
+		if phase == conditionPhase {
+			return metav1.ConditionTrue
+		}
+		return metav1.ConditionFalse
+	}
+	for _, c := range []struct{ conditionType, phase string }{
+		{v1alpha1.ConditionAvailable, v1alpha1.PhaseAvailable},
+		{v1alpha1.ConditionProgressing, v1alpha1.PhaseProgressing},
+		{v1alpha1.ConditionDegraded, v1alpha1.PhaseDegraded},
+	} {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               c.conditionType,
+			Status:             conditionStatus(c.phase),
+			ObservedGeneration: app.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+}
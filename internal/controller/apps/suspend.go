@@ -0,0 +1,205 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const (
+	// SuspendedReplicasAnnotation records on the workload of a suspended
+	// application its number of replicas before the suspension, for a
+	// DaemonSet the number of nodes which ran it.
+	SuspendedReplicasAnnotation = "apps.xinyan.cn/suspended-replicas"
+	// SuspendedNodeLabel is required from the nodes of a suspended DaemonSet,
+	// it is not meant to be set on any node.
+	SuspendedNodeLabel = "apps.xinyan.cn/suspended"
+
+	suspendedReason = "Suspended"
+)
+
+// suspend scales the workload of a suspended application to zero, suspends
+// its CronJobs and routes its Ingress to the maintenance Service. The other
+// resources are left as they are until the application is resumed, the
+// regular reconciliation then restores every resource from the spec. It
+// returns a heldError reporting the suspension.
+func (r *ApplicationReconciler) suspend(ctx context.Context, app *v1alpha1.Application) error {
+	replicas, err := r.suspendWorkload(ctx, app)
+	if err != nil {
+		return err
+	}
+	if err := r.suspendCronJobs(ctx, app); err != nil {
+		return err
+	}
+	if err := r.routeToMaintenance(ctx, app); err != nil {
+		return err
+	}
+	message := "the application is suspended"
+	if replicas != "" {
+		message = fmt.Sprintf("the application is suspended, %s replicas are restored when it is resumed", replicas)
+	}
+	return &heldError{phase: v1alpha1.PhaseSuspended, reason: suspendedReason, message: message}
+}
+
+// suspendWorkload scales the workload of the application to zero, or keeps
+// the pods of a DaemonSet off every node, recording its previous replicas in
+// an annotation. The workload is only changed once, when the application is
+// suspended. It returns the recorded replicas, empty if there is no workload.
+func (r *ApplicationReconciler) suspendWorkload(ctx context.Context, app *v1alpha1.Application) (string, error) {
+	var workload client.Object
+	switch WorkloadKind(app) {
+	case v1alpha1.WorkloadKindStatefulSet:
+		workload = &appsv1.StatefulSet{}
+	case v1alpha1.WorkloadKindDaemonSet:
+		workload = &appsv1.DaemonSet{}
+	default:
+		workload = &appsv1.Deployment{}
+	}
+	if err := r.getOwned(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, workload); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(workload, app) {
+		return "", nil
+	}
+	if replicas, ok := workload.GetAnnotations()[SuspendedReplicasAnnotation]; ok {
+		return replicas, nil
+	}
+
+	patch := client.MergeFromWithOptions(workload.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	var replicas int32
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		replicas = ptr.Deref(w.Spec.Replicas, 1)
+		w.Spec.Replicas = ptr.To[int32](0)
+	case *appsv1.StatefulSet:
+		replicas = ptr.Deref(w.Spec.Replicas, 1)
+		w.Spec.Replicas = ptr.To[int32](0)
+	case *appsv1.DaemonSet:
+		replicas = w.Status.DesiredNumberScheduled
+		if w.Spec.Template.Spec.NodeSelector == nil {
+			w.Spec.Template.Spec.NodeSelector = map[string]string{}
+		}
+		w.Spec.Template.Spec.NodeSelector[SuspendedNodeLabel] = "true"
+	}
+	annotations := workload.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[SuspendedReplicasAnnotation] = strconv.Itoa(int(replicas))
+	workload.SetAnnotations(annotations)
+
+	logf.FromContext(ctx).Info("Suspending "+WorkloadKind(app), "Namespace", app.Namespace, "Name", app.Name,
+		"Replicas", replicas)
+	if err := r.Patch(ctx, workload, patch); err != nil {
+		return "", err
+	}
+	r.recordEvent(app, corev1.EventTypeNormal, suspendedReason,
+		fmt.Sprintf("Scaled %s down from %d replicas", WorkloadKind(app), replicas))
+	return annotations[SuspendedReplicasAnnotation], nil
+}
+
+// unsetReplicas returns the replicas of an existing workload whose
+// application leaves them unset: those recorded when the application was
+// suspended, or else its current replicas, which an autoscaler may manage.
+func unsetReplicas(existing client.Object, current *int32) *int32 {
+	if recorded, ok := existing.GetAnnotations()[SuspendedReplicasAnnotation]; ok {
+		if replicas, err := strconv.ParseInt(recorded, 10, 32); err == nil {
+			return ptr.To(int32(replicas))
+		}
+	}
+	return current
+}
+
+// resumed reports whether an existing workload was suspended, updating it
+// drops the annotation recording its replicas.
+func resumed(existing client.Object) bool {
+	_, ok := existing.GetAnnotations()[SuspendedReplicasAnnotation]
+	return ok
+}
+
+// suspendCronJobs suspends the CronJobs of the application.
+func (r *ApplicationReconciler) suspendCronJobs(ctx context.Context, app *v1alpha1.Application) error {
+	cronJobs := &batchv1.CronJobList{}
+	if err := r.List(ctx, cronJobs, client.InNamespace(app.Namespace), client.MatchingLabels{
+		"app":          app.Name,
+		ComponentLabel: cronJobComponent,
+	}); err != nil {
+		return err
+	}
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		if ptr.Deref(cronJob.Spec.Suspend, false) || !metav1.IsControlledBy(cronJob, app) {
+			continue
+		}
+		patch := client.MergeFrom(cronJob.DeepCopy())
+		cronJob.Spec.Suspend = ptr.To(true)
+		logf.FromContext(ctx).Info("Suspending CronJob", "Namespace", cronJob.Namespace, "Name", cronJob.Name)
+		if err := r.Patch(ctx, cronJob, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// routeToMaintenance routes every path of the Ingress of the application to
+// its maintenance Service, if it has one.
+func (r *ApplicationReconciler) routeToMaintenance(ctx context.Context, app *v1alpha1.Application) error {
+	maintenance := app.Spec.Expose.MaintenanceService
+	if app.Spec.Expose.Mode != "Ingress" || maintenance == nil {
+		return nil
+	}
+	ingress := &networkingv1.Ingress{}
+	if err := r.getOwned(ctx, types.NamespacedName{Namespace: app.Namespace, Name: app.Name}, ingress); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(ingress, app) {
+		return nil
+	}
+	backend := networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+		Name: maintenance.Name,
+		Port: networkingv1.ServiceBackendPort{Number: maintenance.Port},
+	}}
+	existing := ingress.DeepCopy()
+	for i := range ingress.Spec.Rules {
+		if http := ingress.Spec.Rules[i].HTTP; http != nil {
+			for j := range http.Paths {
+				http.Paths[j].Backend = backend
+			}
+		}
+	}
+	if equality.Semantic.DeepEqual(ingress.Spec, existing.Spec) {
+		return nil
+	}
+	logf.FromContext(ctx).Info("Routing Ingress to the maintenance Service", "Namespace", app.Namespace, "Name", app.Name,
+		"Service", maintenance.Name)
+	return r.Patch(ctx, ingress, client.MergeFrom(existing))
+}
@@ -0,0 +1,157 @@
+package apps
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestSuspend(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+	app.UID = "app-uid"
+	app.Spec.CronJobs = []v1alpha1.CronJob{{Name: "report", Schedule: "0 * * * *"}}
+	app.Spec.Expose.MaintenanceService = &v1alpha1.MaintenanceService{Name: "maintenance", Port: 8080}
+	// The fake client refuses unconditional updates of Ingresses, the resource
+	// version is filled in as the API server would allow them.
+	c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			if ingress, ok := obj.(*networkingv1.Ingress); ok && ingress.ResourceVersion == "" {
+				live := &networkingv1.Ingress{}
+				if err := c.Get(ctx, client.ObjectKeyFromObject(ingress), live); err == nil {
+					ingress.ResourceVersion = live.ResourceVersion
+				}
+			}
+			return c.Update(ctx, obj, opts...)
+		},
+	}).Build()
+	r := &ApplicationReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	reconcile := func() {
+		t.Helper()
+		if err := r.createOrUpdateDeployment(ctx, app); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.createOrUpdateCronJobs(ctx, app); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.createOrUpdateIngress(ctx, app); err != nil {
+			t.Fatal(err)
+		}
+	}
+	live := func() (*appsv1.Deployment, *batchv1.CronJob, *networkingv1.Ingress) {
+		t.Helper()
+		deployment, cronJob, ingress := &appsv1.Deployment{}, &batchv1.CronJob{}, &networkingv1.Ingress{}
+		for _, obj := range []client.Object{deployment, ingress} {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(app), obj); err != nil {
+				t.Fatal(err)
+			}
+		}
+		key := types.NamespacedName{Namespace: app.Namespace, Name: app.Name + "-report"}
+		if err := c.Get(ctx, key, cronJob); err != nil {
+			t.Fatal(err)
+		}
+		return deployment, cronJob, ingress
+	}
+	reconcile()
+	deployment, cronJob, ingress := live()
+
+	app.Spec.Suspend = true
+	for range 2 {
+		err := r.suspend(ctx, app)
+		if held := asHeldError(err); held == nil || held.phase != v1alpha1.PhaseSuspended {
+			t.Fatalf("got error %v, want the application to be suspended", err)
+		}
+	}
+	suspended, suspendedCronJob, maintenance := live()
+	if ptr.Deref(suspended.Spec.Replicas, 1) != 0 || suspended.Annotations[SuspendedReplicasAnnotation] != "2" {
+		t.Errorf("got %d replicas and annotations %v, want 0 replicas remembering 2",
+			ptr.Deref(suspended.Spec.Replicas, 1), suspended.Annotations)
+	}
+	if !ptr.Deref(suspendedCronJob.Spec.Suspend, false) {
+		t.Errorf("the CronJob is not suspended")
+	}
+	if backend := maintenance.Spec.Rules[0].HTTP.Paths[0].Backend.Service; backend.Name != "maintenance" ||
+		backend.Port.Number != 8080 {
+		t.Errorf("got Ingress backend %v, want the maintenance Service", backend)
+	}
+
+	app.Spec.Suspend = false
+	reconcile()
+	resumed, resumedCronJob, restored := live()
+	if !equality.Semantic.DeepEqual(resumed.Spec, deployment.Spec) || len(resumed.Annotations) > 0 {
+		t.Errorf("the Deployment was not restored, got replicas %d and annotations %v",
+			ptr.Deref(resumed.Spec.Replicas, 1), resumed.Annotations)
+	}
+	if !equality.Semantic.DeepEqual(resumedCronJob.Spec, cronJob.Spec) {
+		t.Errorf("the CronJob was not restored")
+	}
+	if !equality.Semantic.DeepEqual(restored.Spec, ingress.Spec) {
+		t.Errorf("the Ingress was not restored, got %v", restored.Spec.Rules)
+	}
+}
+
+func TestResumeWithoutReplicas(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+	app.UID = "app-uid"
+	app.Spec.Replicas = nil
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &ApplicationReconciler{Client: c, Scheme: scheme}
+	ctx := context.Background()
+
+	reconcile := func() *appsv1.Deployment {
+		t.Helper()
+		if err := r.createOrUpdateDeployment(ctx, app); err != nil {
+			t.Fatal(err)
+		}
+		deployment := &appsv1.Deployment{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(app), deployment); err != nil {
+			t.Fatal(err)
+		}
+		return deployment
+	}
+	// An autoscaler manages the replicas.
+	deployment := reconcile()
+	patch := client.MergeFrom(deployment.DeepCopy())
+	deployment.Spec.Replicas = ptr.To[int32](3)
+	if err := c.Patch(ctx, deployment, patch); err != nil {
+		t.Fatal(err)
+	}
+	if got := ptr.Deref(reconcile().Spec.Replicas, 1); got != 3 {
+		t.Fatalf("got %d replicas, want the scaled replicas to be kept", got)
+	}
+
+	app.Spec.Suspend = true
+	if err := r.suspend(ctx, app); asHeldError(err) == nil {
+		t.Fatalf("got error %v, want the application to be suspended", err)
+	}
+	app.Spec.Suspend = false
+	for range 2 {
+		resumed := reconcile()
+		if got := ptr.Deref(resumed.Spec.Replicas, 1); got != 3 {
+			t.Errorf("got %d replicas, want the 3 replicas before the suspension", got)
+		}
+		if _, ok := resumed.Annotations[SuspendedReplicasAnnotation]; ok {
+			t.Errorf("got annotations %v, want %s removed", resumed.Annotations, SuspendedReplicasAnnotation)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"embed"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+//go:embed templates/*.yaml
+var builtinTemplates embed.FS
+
+const (
+	// TemplateLabel must be set to "true" on the ConfigMaps holding a template
+	// set. The set is named after the ConfigMap and its keys are the templates
+	// of the resources, deployment.yaml, service.yaml and ingress.yaml.
+	TemplateLabel = "apps.xinyan.cn/template"
+
+	// templateIndex indexes Applications by the template set they use.
+	templateIndex = ".spec.template"
+)
+
+// TemplateSet maps the names of resources, such as deployment, to the source
+// of their template.
+type TemplateSet map[string]string
+
+// templateError reports that the template of a resource could not be rendered.
+type templateError struct {
+	resource string
+	err      error
+}
+
+func (e *templateError) Error() string {
+	return fmt.Sprintf("unable to render %s template: %v", e.resource, e.err)
+}
+
+func (e *templateError) Unwrap() error {
+	return e.err
+}
+
+// RenderTemplate renders a template with the application as data and decodes
+// the result, fields unknown to the resource are rejected.
+func RenderTemplate[T any](name, source string, app *v1alpha1.Application) (*T, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, app); err != nil {
+		return nil, err
+	}
+	obj := new(T)
+	if err := yaml.UnmarshalStrict(buf.Bytes(), obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// NewResourceFromTemplate renders the builtin template of a resource.
+func NewResourceFromTemplate[T any](templateName string, app *v1alpha1.Application) (*T, error) {
+	source, err := builtinTemplates.ReadFile(fmt.Sprintf("templates/%s.yaml", templateName))
+	if err != nil {
+		return nil, err
+	}
+	return RenderTemplate[T](templateName, string(source), app)
+}
+
+// templateSetKey is the context key of the template set loaded by
+// withTemplateSet.
+type templateSetKey struct{}
+
+// withTemplateSet loads the template set of the application into the context,
+// the resources rendered from it during a reconcile then share a single read.
+func (r *ApplicationReconciler) withTemplateSet(ctx context.Context,
+	app *v1alpha1.Application) (context.Context, error) {
+	set, err := r.loadTemplateSet(ctx, app)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, templateSetKey{}, set), nil
+}
+
+// templateSet returns the template set selected by the application, or nil if
+// it uses none. The set loaded by withTemplateSet is used if there is one.
+func (r *ApplicationReconciler) templateSet(ctx context.Context, app *v1alpha1.Application) (TemplateSet, error) {
+	if set, ok := ctx.Value(templateSetKey{}).(TemplateSet); ok {
+		return set, nil
+	}
+	return r.loadTemplateSet(ctx, app)
+}
+
+func (r *ApplicationReconciler) loadTemplateSet(ctx context.Context,
+	app *v1alpha1.Application) (TemplateSet, error) {
+	if app.Spec.Template == "" {
+		return nil, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.reader().Get(ctx, types.NamespacedName{
+		Namespace: r.TemplateNamespace,
+		Name:      app.Spec.Template,
+	}, cm); err != nil {
+		return nil, &templateError{resource: "any", err: err}
+	}
+	if cm.Labels[TemplateLabel] != "true" {
+		return nil, &templateError{resource: "any", err: fmt.Errorf(
+			"ConfigMap %s/%s is not labelled %s=true", cm.Namespace, cm.Name, TemplateLabel)}
+	}
+	set := TemplateSet{}
+	for key, source := range cm.Data {
+		set[strings.TrimSuffix(key, ".yaml")] = source
+	}
+	return set, nil
+}
+
+// templateMetadata makes sure a rendered resource is named, namespaced and
+// labelled like the generated ones, whatever its template says.
+func templateMetadata(app *v1alpha1.Application, meta *metav1.ObjectMeta) {
+	metaData := NewMetadata(app)
+	meta.Name = metaData.Name
+	meta.Namespace = metaData.Namespace
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+	maps.Copy(meta.Labels, metaData.Labels)
+}
+
+// newDeployment returns the Deployment of the application, rendered from its
+// template set if it has a deployment template.
+func (r *ApplicationReconciler) newDeployment(ctx context.Context,
+	app *v1alpha1.Application) (*appsv1.Deployment, error) {
+	set, err := r.templateSet(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := set["deployment"]
+	if !ok {
+		return NewDeployment(app), nil
+	}
+	deployment, err := RenderTemplate[appsv1.Deployment]("deployment", source, app)
+	if err != nil {
+		return nil, &templateError{resource: "deployment", err: err}
+	}
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		return nil, &templateError{resource: "deployment", err: fmt.Errorf("no container in pod template")}
+	}
+	templateMetadata(app, &deployment.ObjectMeta)
+	deployment.Spec.Selector = appSelector(app)
+	if deployment.Spec.Template.Labels == nil {
+		deployment.Spec.Template.Labels = map[string]string{}
+	}
+	deployment.Spec.Template.Labels["app"] = app.Name
+	mergePodTemplate(&deployment.Spec.Template, NewPodTemplate(app))
+	return deployment, nil
+}
+
+// mergePodTemplate fills in the pod settings a template leaves out from the
+// generated pod template: the security contexts, the ServiceAccount, the
+// scheduling constraints and the image pull secrets, as well as the init
+// containers, sidecars and volumes the template does not define.
+func mergePodTemplate(template *corev1.PodTemplateSpec, generated corev1.PodTemplateSpec) {
+	spec, gen := &template.Spec, &generated.Spec
+	spec.SecurityContext = cmp.Or(spec.SecurityContext, gen.SecurityContext)
+	spec.ServiceAccountName = cmp.Or(spec.ServiceAccountName, gen.ServiceAccountName)
+	spec.AutomountServiceAccountToken = cmp.Or(spec.AutomountServiceAccountToken, gen.AutomountServiceAccountToken)
+	spec.Affinity = cmp.Or(spec.Affinity, gen.Affinity)
+	if len(spec.NodeSelector) == 0 {
+		spec.NodeSelector = gen.NodeSelector
+	}
+	if len(spec.Tolerations) == 0 {
+		spec.Tolerations = gen.Tolerations
+	}
+	if len(spec.TopologySpreadConstraints) == 0 {
+		spec.TopologySpreadConstraints = gen.TopologySpreadConstraints
+	}
+	if len(spec.ImagePullSecrets) == 0 {
+		spec.ImagePullSecrets = gen.ImagePullSecrets
+	}
+	// The first container is the application container in both.
+	spec.Containers[0].SecurityContext = cmp.Or(spec.Containers[0].SecurityContext, gen.Containers[0].SecurityContext)
+	spec.InitContainers = appendMissing(spec.InitContainers, gen.InitContainers,
+		func(c corev1.Container) string { return c.Name })
+	spec.Containers = appendMissing(spec.Containers, gen.Containers[1:],
+		func(c corev1.Container) string { return c.Name })
+	spec.Volumes = appendMissing(spec.Volumes, gen.Volumes,
+		func(v corev1.Volume) string { return v.Name })
+	mountTmp(spec)
+}
+
+// appendMissing appends the items of from whose name is not in to.
+func appendMissing[T any](to, from []T, name func(T) string) []T {
+	for _, item := range from {
+		if !slices.ContainsFunc(to, func(o T) bool { return name(o) == name(item) }) {
+			to = append(to, item)
+		}
+	}
+	return to
+}
+
+// newService returns the Service of the application, rendered from its
+// template set if it has a service template.
+func (r *ApplicationReconciler) newService(ctx context.Context,
+	app *v1alpha1.Application) (*corev1.Service, error) {
+	set, err := r.templateSet(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := set["service"]
+	if !ok {
+		return NewService(app), nil
+	}
+	service, err := RenderTemplate[corev1.Service]("service", source, app)
+	if err != nil {
+		return nil, &templateError{resource: "service", err: err}
+	}
+	templateMetadata(app, &service.ObjectMeta)
+	return service, nil
+}
+
+// newIngress returns the Ingress of the application, rendered from its
+// template set if it has an ingress template.
+func (r *ApplicationReconciler) newIngress(ctx context.Context,
+	app *v1alpha1.Application) (*networkingv1.Ingress, error) {
+	set, err := r.templateSet(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	source, ok := set["ingress"]
+	if !ok {
+		return NewIngress(app), nil
+	}
+	ingress, err := RenderTemplate[networkingv1.Ingress]("ingress", source, app)
+	if err != nil {
+		return nil, &templateError{resource: "ingress", err: err}
+	}
+	templateMetadata(app, &ingress.ObjectMeta)
+	return ingress, nil
+}
+
+// indexTemplate is the indexer func of templateIndex.
+func indexTemplate(obj client.Object) []string {
+	app, ok := obj.(*v1alpha1.Application)
+	if !ok || app.Spec.Template == "" {
+		return nil
+	}
+	return []string{app.Spec.Template}
+}
+
+// findApplicationsForTemplate maps a template ConfigMap to the Applications
+// using it.
+func (r *ApplicationReconciler) findApplicationsForTemplate(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetNamespace() != r.TemplateNamespace || obj.GetLabels()[TemplateLabel] != "true" {
+		return nil
+	}
+	apps := &v1alpha1.ApplicationList{}
+	if err := r.List(ctx, apps, client.MatchingFields{templateIndex: obj.GetName()}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(apps.Items))
+	for _, app := range apps.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: app.Namespace,
+			Name:      app.Name,
+		}})
+	}
+	return requests
+}
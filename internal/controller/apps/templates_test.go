@@ -0,0 +1,165 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const deploymentTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ignored
+  namespace: ignored
+  labels:
+    tier: web
+spec:
+  {{- with .Spec.Replicas }}
+  replicas: {{ . }}
+  {{- end }}
+  template:
+    spec:
+      containers:
+        - name: {{ .Name }}
+          image: {{ .Spec.Image }}
+`
+
+func TestTemplateSet(t *testing.T) {
+	newTemplate := func(name string, labelled bool, data map[string]string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "system"},
+			Data:       data,
+		}
+		if labelled {
+			cm.Labels = map[string]string{TemplateLabel: "true"}
+		}
+		return cm
+	}
+	r := &ApplicationReconciler{
+		Client: fake.NewClientBuilder().WithObjects(
+			newTemplate("web", true, map[string]string{"deployment.yaml": deploymentTemplate}),
+			newTemplate("unlabelled", false, map[string]string{"deployment.yaml": deploymentTemplate}),
+			newTemplate("broken", true, map[string]string{"deployment.yaml": "{{ .Spec.Unknown }}"}),
+		).Build(),
+		TemplateNamespace: "system",
+	}
+	ctx := context.Background()
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+
+	app.Spec.Template = "web"
+	deployment, err := r.newDeployment(ctx, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Name != app.Name || deployment.Namespace != app.Namespace ||
+		deployment.Labels["tier"] != "web" || deployment.Labels[ManagedByLabel] != ManagedByValue {
+		t.Errorf("got metadata %v", deployment.ObjectMeta)
+	}
+	if !equality.Semantic.DeepEqual(deployment.Spec.Selector, appSelector(app)) ||
+		deployment.Spec.Template.Labels["app"] != app.Name {
+		t.Errorf("got selector %v and pod labels %v", deployment.Spec.Selector, deployment.Spec.Template.Labels)
+	}
+	// The set has no service template.
+	if service, err := r.newService(ctx, app); err != nil || !equality.Semantic.DeepEqual(service, NewService(app)) {
+		t.Errorf("got %v, %v, want the generated Service", service, err)
+	}
+
+	for _, name := range []string{"unlabelled", "broken", "missing", "builtin"} {
+		app.Spec.Template = name
+		_, err := r.newDeployment(ctx, app)
+		var tmplErr *templateError
+		if !errors.As(err, &tmplErr) {
+			t.Errorf("got %v for template %s, want a template error", err, name)
+		}
+	}
+}
+
+func TestBuiltinTemplateWithoutReplicas(t *testing.T) {
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+	app.Spec.Replicas = nil
+	deployment, err := NewResourceFromTemplate[appsv1.Deployment]("deployment", app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deployment.Spec.Replicas != nil {
+		t.Errorf("got replicas %d, want them unset", *deployment.Spec.Replicas)
+	}
+}
+
+func TestTemplateMergesPodSettings(t *testing.T) {
+	template := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "system", Labels: map[string]string{TemplateLabel: "true"}},
+		Data:       map[string]string{"deployment.yaml": deploymentTemplate},
+	}
+	gets := 0
+	r := &ApplicationReconciler{
+		Client: fake.NewClientBuilder().WithObjects(template).WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+				opts ...client.GetOption) error {
+				gets++
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build(),
+		TemplateNamespace: "system",
+	}
+	app := newResource[v1alpha1.Application]("testdata/app_ing_cr.yaml")
+	app.Spec.Template = "web"
+	app.Spec.Security = &v1alpha1.Security{ReadOnlyRootFilesystem: ptr.To(true)}
+	app.Spec.ServiceAccount = &v1alpha1.ServiceAccount{}
+	app.Spec.NodeSelector = map[string]string{"disk": "ssd"}
+	app.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	app.Spec.Sidecars = []v1alpha1.ExtraContainer{{Container: &corev1.Container{Name: "proxy", Image: "envoy"}}}
+
+	ctx, err := r.withTemplateSet(context.Background(), app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment, err := r.newDeployment(ctx, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.newService(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.newIngress(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	if gets != 1 {
+		t.Errorf("got %d reads of the template set, want 1", gets)
+	}
+
+	spec := deployment.Spec.Template.Spec
+	if spec.ServiceAccountName != app.Name || spec.NodeSelector["disk"] != "ssd" ||
+		len(spec.ImagePullSecrets) != 1 || spec.SecurityContext == nil {
+		t.Errorf("got pod spec %v, want the generated pod settings", spec)
+	}
+	if len(spec.Containers) != 2 || spec.Containers[1].Name != "proxy" {
+		t.Errorf("got containers %v, want the sidecar appended", spec.Containers)
+	}
+	if sc := spec.Containers[0].SecurityContext; sc == nil || !ptr.Deref(sc.ReadOnlyRootFilesystem, false) {
+		t.Errorf("got security context %v for the application container", sc)
+	}
+	tmp := 0
+	for _, v := range spec.Volumes {
+		if v.Name == tmpVolume {
+			tmp++
+		}
+	}
+	if tmp != 1 || !slices.ContainsFunc(spec.Containers[0].VolumeMounts, func(m corev1.VolumeMount) bool {
+		return m.MountPath == "/tmp"
+	}) {
+		t.Errorf("got volumes %v and mounts %v, want one /tmp volume mounted", spec.Volumes, spec.Containers[0].VolumeMounts)
+	}
+}
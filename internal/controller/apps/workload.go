@@ -0,0 +1,498 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apps
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// WorkloadKind returns the workload kind of the application, Deployment if unset.
+func WorkloadKind(app *v1alpha1.Application) string {
+	if app.Spec.WorkloadKind == "" {
+		return v1alpha1.WorkloadKindDeployment
+	}
+	return app.Spec.WorkloadKind
+}
+
+// HeadlessServiceName returns the name of the headless Service governing the
+// StatefulSet of the application.
+func HeadlessServiceName(app *v1alpha1.Application) string {
+	return app.Name + "-headless"
+}
+
+func NewStatefulSet(app *v1alpha1.Application) *appsv1.StatefulSet {
+	metaData := NewMetadata(app)
+	statefulSet := &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metaData,
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": app.Name,
+				},
+			},
+			Replicas:    app.Spec.Replicas,
+			ServiceName: HeadlessServiceName(app),
+			Template:    NewPodTemplate(app),
+		},
+	}
+	if app.Spec.Persistence == nil {
+		return statefulSet
+	}
+	container := &statefulSet.Spec.Template.Spec.Containers[0]
+	for _, claim := range app.Spec.Persistence.VolumeClaimTemplates {
+		accessModes := claim.AccessModes
+		if len(accessModes) == 0 {
+			accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+		}
+		statefulSet.Spec.VolumeClaimTemplates = append(statefulSet.Spec.VolumeClaimTemplates,
+			corev1.PersistentVolumeClaim{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "PersistentVolumeClaim",
+					APIVersion: "v1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   claim.Name,
+					Labels: templateLabels(app),
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      accessModes,
+					StorageClassName: claim.StorageClassName,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceStorage: claim.Size,
+						},
+					},
+				},
+			})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      claim.Name,
+			MountPath: claim.MountPath,
+		})
+	}
+	return statefulSet
+}
+
+func NewDaemonSet(app *v1alpha1.Application) *appsv1.DaemonSet {
+	metaData := NewMetadata(app)
+	daemonSet := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metaData,
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": app.Name,
+				},
+			},
+			Template: NewPodTemplate(app),
+		},
+	}
+	if strategy := app.Spec.UpdateStrategy; strategy != nil {
+		daemonSet.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+			Type: appsv1.RollingUpdateDaemonSetStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+				MaxUnavailable: strategy.MaxUnavailable,
+				MaxSurge:       strategy.MaxSurge,
+			},
+		}
+	}
+	return daemonSet
+}
+
+func NewHeadlessService(app *v1alpha1.Application) *corev1.Service {
+	metaData := NewMetadata(app)
+	metaData.Name = HeadlessServiceName(app)
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metaData,
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{
+				"app": app.Name,
+			},
+			ClusterIP:                corev1.ClusterIPNone,
+			PublishNotReadyAddresses: true,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       app.Spec.Port,
+					TargetPort: intstr.FromString("http"),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+			Type: corev1.ServiceTypeClusterIP,
+		},
+	}
+}
+
+// workloadState is the observed state of the workload running an application.
+type workloadState struct {
+	kind     string
+	replicas int32
+	ready    int32
+	updated  int32
+	// rolledOut is true when every pod runs the latest pod template.
+	rolledOut bool
+	template  *corev1.PodTemplateSpec
+	ordinals  []v1alpha1.OrdinalStatus
+}
+
+// available reports whether every desired pod of the workload is ready.
+func (s *workloadState) available() bool {
+	return s != nil && s.rolledOut && s.ready >= s.replicas
+}
+
+// observeWorkload returns the state of the workload of the application's
+// kind, or nil if it does not exist yet or is not controlled by the application.
+func (r *ApplicationReconciler) observeWorkload(
+	ctx context.Context, app *v1alpha1.Application) (*workloadState, error) {
+	key := types.NamespacedName{Namespace: app.Namespace, Name: app.Name}
+	switch kind := WorkloadKind(app); kind {
+	case v1alpha1.WorkloadKindStatefulSet:
+		statefulSet := &appsv1.StatefulSet{}
+		if err := r.Get(ctx, key, statefulSet); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(statefulSet, app) {
+			return nil, nil
+		}
+		state := &workloadState{
+			kind:      kind,
+			replicas:  ptr.Deref(statefulSet.Spec.Replicas, 1),
+			ready:     statefulSet.Status.ReadyReplicas,
+			updated:   statefulSet.Status.UpdatedReplicas,
+			rolledOut: statefulSetRolledOut(statefulSet),
+			template:  &statefulSet.Spec.Template,
+		}
+		ordinals, err := r.observeOrdinals(ctx, app, state.replicas)
+		if err != nil {
+			return nil, err
+		}
+		state.ordinals = ordinals
+		return state, nil
+	case v1alpha1.WorkloadKindDaemonSet:
+		daemonSet := &appsv1.DaemonSet{}
+		if err := r.Get(ctx, key, daemonSet); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(daemonSet, app) {
+			return nil, nil
+		}
+		return &workloadState{
+			kind:      kind,
+			replicas:  daemonSet.Status.DesiredNumberScheduled,
+			ready:     daemonSet.Status.NumberReady,
+			updated:   daemonSet.Status.UpdatedNumberScheduled,
+			rolledOut: daemonSetRolledOut(daemonSet),
+			template:  &daemonSet.Spec.Template,
+		}, nil
+	default:
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deployment); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(deployment, app) {
+			return nil, nil
+		}
+		return &workloadState{
+			kind:      kind,
+			replicas:  ptr.Deref(deployment.Spec.Replicas, 1),
+			ready:     deployment.Status.ReadyReplicas,
+			updated:   deployment.Status.UpdatedReplicas,
+			rolledOut: deploymentRolledOut(deployment),
+			template:  &deployment.Spec.Template,
+		}, nil
+	}
+}
+
+// observeOrdinals reports the readiness of each pod of the StatefulSet.
+func (r *ApplicationReconciler) observeOrdinals(ctx context.Context,
+	app *v1alpha1.Application, replicas int32) ([]v1alpha1.OrdinalStatus, error) {
+	pods := &corev1.PodList{}
+	if err := r.reader().List(ctx, pods, client.InNamespace(app.Namespace),
+		client.MatchingLabels{"app": app.Name}); err != nil {
+		return nil, err
+	}
+	ready := map[string]bool{}
+	for _, pod := range pods.Items {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+				ready[pod.Name] = true
+			}
+		}
+	}
+	ordinals := make([]v1alpha1.OrdinalStatus, 0, replicas)
+	for i := range replicas {
+		name := fmt.Sprintf("%s-%d", app.Name, i)
+		ordinals = append(ordinals, v1alpha1.OrdinalStatus{
+			Ordinal: i,
+			Pod:     name,
+			Ready:   ready[name],
+		})
+	}
+	return ordinals, nil
+}
+
+// statefulSetRolledOut reports whether every replica of the StatefulSet runs
+// the latest pod template.
+func statefulSetRolledOut(statefulSet *appsv1.StatefulSet) bool {
+	if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return false
+	}
+	replicas := ptr.Deref(statefulSet.Spec.Replicas, 1)
+	status := statefulSet.Status
+	return status.UpdatedReplicas == replicas && status.Replicas == replicas &&
+		status.CurrentRevision == status.UpdateRevision
+}
+
+// daemonSetRolledOut reports whether every scheduled pod of the DaemonSet runs
+// the latest pod template and is available.
+func daemonSetRolledOut(daemonSet *appsv1.DaemonSet) bool {
+	if daemonSet.Generation > daemonSet.Status.ObservedGeneration {
+		return false
+	}
+	status := daemonSet.Status
+	return status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+		status.NumberAvailable == status.DesiredNumberScheduled
+}
+
+// renderPodTemplate stamps the config checksum on the pod template and mounts
+// the configFiles, whose ConfigMap is created by createConfigFiles.
+func (r *ApplicationReconciler) renderPodTemplate(ctx context.Context,
+	app *v1alpha1.Application, template *corev1.PodTemplateSpec) error {
+	checksum, err := r.configChecksum(ctx, app)
+	if err != nil {
+		return err
+	}
+	if checksum != "" {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[ConfigChecksumAnnotation] = checksum
+	}
+	configFiles, err := NewConfigFilesConfigMap(app)
+	if err != nil {
+		return err
+	}
+	if configFiles != nil {
+		MountConfigFiles(app, &template.Spec, configFiles)
+	}
+	return nil
+}
+
+func (r *ApplicationReconciler) createOrUpdateStatefulSet(
+	ctx context.Context, app *v1alpha1.Application) error {
+	statefulSet, err := r.desiredStatefulSet(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = controllerutil.SetControllerReference(app, statefulSet, r.Scheme)
+	if err != nil {
+		return err
+	}
+	existingStatefulSet := &appsv1.StatefulSet{}
+	if err = r.getOwned(ctx, types.NamespacedName{
+		Namespace: app.Namespace,
+		Name:      app.Name,
+	}, existingStatefulSet); err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.runPreDeployHooks(ctx, app, ""); err != nil {
+				return err
+			}
+			logf.FromContext(ctx).Info("Creating StatefulSet", "Namespace",
+				app.Namespace, "Name", app.Name)
+			return r.Create(ctx, statefulSet)
+		}
+		return err
+	}
+	if err := r.adopt(ctx, app, existingStatefulSet, statefulSet); err != nil {
+		return err
+	}
+	if err := r.runPreDeployHooks(ctx, app,
+		existingStatefulSet.Spec.Template.Spec.Containers[0].Image); err != nil {
+		return err
+	}
+
+	if statefulSet.Spec.Replicas == nil {
+		statefulSet.Spec.Replicas = unsetReplicas(existingStatefulSet, existingStatefulSet.Spec.Replicas)
+	}
+	err = r.Update(ctx, statefulSet, client.DryRunAll)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(statefulSet.Spec, existingStatefulSet.Spec) ||
+		!equality.Semantic.DeepEqual(statefulSet.Labels, existingStatefulSet.Labels) ||
+		!equality.Semantic.DeepEqual(statefulSet.OwnerReferences, existingStatefulSet.OwnerReferences) ||
+		resumed(existingStatefulSet) {
+		logf.FromContext(ctx).Info("Updating StatefulSet", "Namespace",
+			app.Namespace, "Name", app.Name)
+		return r.Update(ctx, statefulSet)
+	}
+	return nil
+}
+
+func (r *ApplicationReconciler) createOrUpdateDaemonSet(
+	ctx context.Context, app *v1alpha1.Application) error {
+	daemonSet, err := r.desiredDaemonSet(ctx, app)
+	if err != nil {
+		return err
+	}
+	err = controllerutil.SetControllerReference(app, daemonSet, r.Scheme)
+	if err != nil {
+		return err
+	}
+	existingDaemonSet := &appsv1.DaemonSet{}
+	if err = r.getOwned(ctx, types.NamespacedName{
+		Namespace: app.Namespace,
+		Name:      app.Name,
+	}, existingDaemonSet); err != nil {
+		if errors.IsNotFound(err) {
+			if err := r.runPreDeployHooks(ctx, app, ""); err != nil {
+				return err
+			}
+			logf.FromContext(ctx).Info("Creating DaemonSet", "Namespace",
+				app.Namespace, "Name", app.Name)
+			return r.Create(ctx, daemonSet)
+		}
+		return err
+	}
+	if err := r.adopt(ctx, app, existingDaemonSet, daemonSet); err != nil {
+		return err
+	}
+	if err := r.runPreDeployHooks(ctx, app,
+		existingDaemonSet.Spec.Template.Spec.Containers[0].Image); err != nil {
+		return err
+	}
+
+	err = r.Update(ctx, daemonSet, client.DryRunAll)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(daemonSet.Spec, existingDaemonSet.Spec) ||
+		!equality.Semantic.DeepEqual(daemonSet.Labels, existingDaemonSet.Labels) ||
+		!equality.Semantic.DeepEqual(daemonSet.OwnerReferences, existingDaemonSet.OwnerReferences) ||
+		resumed(existingDaemonSet) {
+		logf.FromContext(ctx).Info("Updating DaemonSet", "Namespace",
+			app.Namespace, "Name", app.Name)
+		return r.Update(ctx, daemonSet)
+	}
+	return nil
+}
+
+func (r *ApplicationReconciler) createOrUpdateHeadlessService(
+	ctx context.Context, app *v1alpha1.Application) error {
+	service := NewHeadlessService(app)
+	err := controllerutil.SetControllerReference(app, service, r.Scheme)
+	if err != nil {
+		return err
+	}
+	existingService := &corev1.Service{}
+	if err = r.getOwned(ctx, types.NamespacedName{
+		Namespace: service.Namespace,
+		Name:      service.Name,
+	}, existingService); err != nil {
+		if errors.IsNotFound(err) {
+			logf.FromContext(ctx).Info("Creating Service", "Namespace",
+				service.Namespace, "Name", service.Name)
+			return r.Create(ctx, service, client.FieldOwner(app.Name))
+		}
+		return err
+	}
+	if err := r.adopt(ctx, app, existingService, service); err != nil {
+		return err
+	}
+
+	err = r.Update(ctx, service, client.DryRunAll)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(service.Spec, existingService.Spec) ||
+		!equality.Semantic.DeepEqual(service.Labels, existingService.Labels) ||
+		!equality.Semantic.DeepEqual(service.OwnerReferences, existingService.OwnerReferences) {
+		logf.FromContext(ctx).Info("Updating Service", "Namespace",
+			service.Namespace, "Name", service.Name)
+		return r.Update(ctx, service, client.FieldOwner(app.Name))
+	}
+	return nil
+}
+
+// cleanupWorkloads deletes the workloads of the kinds the application no
+// longer uses. They are kept until the workload of the current kind is
+// available, so that switching kinds does not interrupt the application.
+// The PersistentVolumeClaims of a deleted StatefulSet are retained.
+func (r *ApplicationReconciler) cleanupWorkloads(ctx context.Context,
+	app *v1alpha1.Application, state *workloadState) error {
+	if !state.available() {
+		return nil
+	}
+	var stale []client.Object
+	if state.kind != v1alpha1.WorkloadKindDeployment {
+		stale = append(stale, &appsv1.Deployment{})
+	}
+	if state.kind != v1alpha1.WorkloadKindStatefulSet {
+		stale = append(stale, &appsv1.StatefulSet{}, &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: HeadlessServiceName(app)},
+		})
+	}
+	if state.kind != v1alpha1.WorkloadKindDaemonSet {
+		stale = append(stale, &appsv1.DaemonSet{})
+	}
+	for _, obj := range stale {
+		name := obj.GetName()
+		if name == "" {
+			name = app.Name
+		}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: app.Namespace, Name: name}, obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(obj, app) {
+			continue
+		}
+		logf.FromContext(ctx).Info("Deleting previous workload", "Kind", fmt.Sprintf("%T", obj),
+			"Namespace", app.Namespace, "Name", name)
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
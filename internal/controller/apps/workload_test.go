@@ -0,0 +1,98 @@
+package apps
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func TestNewStatefulSet(t *testing.T) {
+	type args struct {
+		app *v1alpha1.Application
+	}
+	tests := []struct {
+		name string
+		args args
+		want *appsv1.StatefulSet
+	}{
+		{
+			name: "Test StatefulSet Generation",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_sts_cr.yaml"),
+			},
+			want: newResource[appsv1.StatefulSet](
+				"testdata/sts_expect.yaml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewStatefulSet(tt.args.app)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDaemonSet(t *testing.T) {
+	type args struct {
+		app *v1alpha1.Application
+	}
+	tests := []struct {
+		name string
+		args args
+		want *appsv1.DaemonSet
+	}{
+		{
+			name: "Test DaemonSet Generation",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_ds_cr.yaml"),
+			},
+			want: newResource[appsv1.DaemonSet](
+				"testdata/ds_expect.yaml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDaemonSet(tt.args.app)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHeadlessService(t *testing.T) {
+	type args struct {
+		app *v1alpha1.Application
+	}
+	tests := []struct {
+		name string
+		args args
+		want *corev1.Service
+	}{
+		{
+			name: "Test Headless Service Generation",
+			args: args{
+				app: newResource[v1alpha1.Application](
+					"testdata/app_sts_cr.yaml"),
+			},
+			want: newResource[corev1.Service](
+				"testdata/svc_headless_expect.yaml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewHeadlessService(tt.args.app)
+			if !equality.Semantic.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package imagepolicy restricts the images Applications may run, the policy
+// is configured for the whole operator and enforced by both the webhook and
+// the controller.
+package imagepolicy
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/registry"
+)
+
+// Policy restricts the images of Applications, a nil Policy allows any image.
+type Policy struct {
+	// AllowedRegistries are the registries images must come from, a registry
+	// host optionally followed by a repository path such as
+	// docker.io/library. Any registry is allowed when it is empty.
+	AllowedRegistries []string `json:"allowedRegistries,omitempty"`
+	// ForbidLatest rejects images tagged latest, including images with
+	// neither a tag nor a digest.
+	ForbidLatest bool `json:"forbidLatest,omitempty"`
+	// RequireDigestNamespaces are the namespaces whose Applications must pin
+	// their images by digest.
+	RequireDigestNamespaces []string `json:"requireDigestNamespaces,omitempty"`
+	// ResolveDigests makes the controller pin the image of Applications to
+	// the digest its tag resolves to when the image changes.
+	ResolveDigests bool `json:"resolveDigests,omitempty"`
+}
+
+// Load reads a policy from a YAML file.
+func Load(path string) (*Policy, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(b, policy); err != nil {
+		return nil, fmt.Errorf("invalid image policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Check returns an error if the policy does not allow the image in the
+// namespace.
+func (p *Policy) Check(image, namespace string) error {
+	if p == nil {
+		return nil
+	}
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return err
+	}
+	if err := p.CheckRegistry(ref); err != nil {
+		return err
+	}
+	if p.ForbidLatest && ref.Tag == "latest" {
+		return fmt.Errorf("the latest tag is not allowed")
+	}
+	if ref.Digest == "" && slices.Contains(p.RequireDigestNamespaces, namespace) {
+		return fmt.Errorf("images must be pinned by digest in namespace %s", namespace)
+	}
+	return nil
+}
+
+// CheckRegistry returns an error if the policy does not allow the registry
+// of the image.
+func (p *Policy) CheckRegistry(ref registry.Reference) error {
+	if p == nil || len(p.AllowedRegistries) == 0 || slices.ContainsFunc(p.AllowedRegistries, func(allowed string) bool {
+		allowed = strings.TrimSuffix(allowed, "/")
+		return ref.Name() == allowed || strings.HasPrefix(ref.Name(), allowed+"/")
+	}) {
+		return nil
+	}
+	return fmt.Errorf("registry of %s is not one of %s", ref.Name(), strings.Join(p.AllowedRegistries, ", "))
+}
+
+// Validate checks the images of the application and of its hooks and extra
+// containers against the policy. Images of container presets are only known
+// to the controller, which validates them once they are resolved.
+func (p *Policy) Validate(app *v1alpha1.Application) field.ErrorList {
+	var errs field.ErrorList
+	check := func(image string, path *field.Path) {
+		if err := p.Check(image, app.Namespace); err != nil {
+			errs = append(errs, field.Invalid(path, image, err.Error()))
+		}
+	}
+	check(app.Spec.Image, field.NewPath("spec", "image"))
+	if app.Spec.Hooks != nil {
+		for i, hook := range app.Spec.Hooks.PreDeploy {
+			if hook.Image != "" {
+				check(hook.Image, field.NewPath("spec", "hooks", "preDeploy").Index(i).Child("image"))
+			}
+		}
+	}
+	for _, extra := range []struct {
+		name       string
+		containers []v1alpha1.ExtraContainer
+	}{
+		{"initContainers", app.Spec.InitContainers},
+		{"sidecars", app.Spec.Sidecars},
+	} {
+		for i, c := range extra.containers {
+			if c.Container != nil && c.Container.Image != "" {
+				check(c.Container.Image, field.NewPath("spec", extra.name).Index(i).Child("container", "image"))
+			}
+		}
+	}
+	return errs
+}
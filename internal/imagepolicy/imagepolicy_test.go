@@ -0,0 +1,73 @@
+package imagepolicy
+
+import (
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestCheck(t *testing.T) {
+	policy, err := Load("testdata/policy.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		image     string
+		namespace string
+		wantErr   bool
+	}{
+		{image: "registry.example.com/team/app:v1", namespace: "dev"},
+		{image: "nginx:1.25", namespace: "dev"},
+		{image: "bitnami/redis:7", namespace: "dev", wantErr: true},
+		{image: "registry.example.com.evil.io/app:v1", namespace: "dev", wantErr: true},
+		{image: "registry.example.com/team/app", namespace: "dev", wantErr: true},
+		{image: "registry.example.com/team/app:latest", namespace: "dev", wantErr: true},
+		{image: "registry.example.com/team/app:v1", namespace: "prod", wantErr: true},
+		{image: "registry.example.com/team/app@" + digest, namespace: "prod"},
+		{image: "registry.example.com/team/App:v1", namespace: "dev", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace+"/"+tt.image, func(t *testing.T) {
+			if err := policy.Check(tt.image, tt.namespace); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var none *Policy
+	if err := none.Check("anything:latest", "prod"); err != nil {
+		t.Errorf("nil policy rejected an image: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	policy := &Policy{ForbidLatest: true}
+	app := &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dev"},
+		Spec: v1alpha1.ApplicationSpec{
+			Image: "nginx:latest",
+			Hooks: &v1alpha1.Hooks{PreDeploy: []v1alpha1.HookJob{
+				{Name: "migrate", Image: "migrate:v1"},
+				{Name: "seed", Image: "seed"},
+			}},
+			Sidecars: []v1alpha1.ExtraContainer{
+				{Preset: "proxy"},
+				{Container: &corev1.Container{Name: "agent", Image: "agent"}},
+			},
+		},
+	}
+	var got []string
+	for _, err := range policy.Validate(app) {
+		got = append(got, err.Field)
+	}
+	want := []string{"spec.image", "spec.hooks.preDeploy[1].image", "spec.sidecars[1].container.image"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Validate() reported %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,511 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importer converts hand-managed Deployments, Services and Ingresses
+// into equivalent Applications.
+package importer
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+)
+
+// Result is the Application imported from a Deployment and the Service and
+// Ingress exposing it.
+type Result struct {
+	Application *v1alpha1.Application
+	Deployment  *appsv1.Deployment
+	// Service and Ingress are nil when none exposes the Deployment.
+	Service *corev1.Service
+	Ingress *networkingv1.Ingress
+	// Issues are the settings of the objects the Application does not
+	// represent, they are lost once the operator manages the objects.
+	Issues []string
+	// Conflicts keep the operator from managing the objects, they cannot be
+	// adopted by the Application.
+	Conflicts []string
+}
+
+func (r *Result) issuef(format string, args ...any) {
+	r.Issues = append(r.Issues, fmt.Sprintf(format, args...))
+}
+
+func (r *Result) conflictf(format string, args ...any) {
+	r.Conflicts = append(r.Conflicts, fmt.Sprintf(format, args...))
+}
+
+// Import returns the Application of every Deployment, exposed by the first
+// Service selecting its pods and the first Ingress routing to that Service.
+func Import(deployments []appsv1.Deployment, services []corev1.Service,
+	ingresses []networkingv1.Ingress) []*Result {
+	results := make([]*Result, 0, len(deployments))
+	for i := range deployments {
+		r := &Result{Deployment: &deployments[i]}
+		r.Application = r.importDeployment()
+		r.Service = r.matchService(services)
+		if r.Service != nil {
+			r.Ingress = r.matchIngress(ingresses)
+		}
+		r.importExpose()
+		for _, obj := range []metav1.Object{r.Deployment, r.Service, r.Ingress} {
+			if reflect.ValueOf(obj).IsNil() {
+				continue
+			}
+			if owner := metav1.GetControllerOf(obj); owner != nil {
+				r.conflictf("%s is controlled by %s %s", obj.GetName(), owner.Kind, owner.Name)
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func (r *Result) importDeployment() *v1alpha1.Application {
+	d := r.Deployment
+	app := &v1alpha1.Application{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Application",
+			APIVersion: v1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      d.Name,
+			Namespace: d.Namespace,
+			Labels:    maps.Clone(d.Labels),
+		},
+		Spec: v1alpha1.ApplicationSpec{
+			Replicas: d.Spec.Replicas,
+		},
+	}
+	delete(app.Labels, "app")
+	delete(app.Labels, apps.ManagedByLabel)
+	if len(app.Labels) == 0 {
+		app.Labels = nil
+	}
+
+	if selector := d.Spec.Selector; selector == nil || len(selector.MatchExpressions) > 0 ||
+		!maps.Equal(selector.MatchLabels, map[string]string{"app": d.Name}) {
+		// The selector of a Deployment is immutable.
+		r.conflictf("the selector of Deployment %s is not app=%s", d.Name, d.Name)
+	}
+	spec := d.Spec.DeepCopy()
+	spec.Replicas, spec.Selector, spec.Template = nil, nil, corev1.PodTemplateSpec{}
+	switch spec.Strategy.Type {
+	case appsv1.RollingUpdateDeploymentStrategyType, "":
+		if ru := spec.Strategy.RollingUpdate; ru != nil && !defaultRollingUpdate(ru) {
+			app.Spec.UpdateStrategy = &v1alpha1.UpdateStrategy{
+				MaxUnavailable: ru.MaxUnavailable,
+				MaxSurge:       ru.MaxSurge,
+			}
+		}
+		spec.Strategy = appsv1.DeploymentStrategy{}
+	}
+	if ptr.Deref(spec.RevisionHistoryLimit, 10) == 10 {
+		spec.RevisionHistoryLimit = nil
+	}
+	if ptr.Deref(spec.ProgressDeadlineSeconds, 600) == 600 {
+		spec.ProgressDeadlineSeconds = nil
+	}
+	r.unmapped("Deployment "+d.Name, "spec", spec)
+
+	template := d.Spec.Template
+	if len(template.Annotations) > 0 {
+		r.issuef("the pod template annotations of Deployment %s are not represented", d.Name)
+	}
+	for k, v := range template.Labels {
+		if k != "app" && k != apps.ManagedByLabel && d.Labels[k] != v {
+			r.issuef("the pod template label %s of Deployment %s is not represented", k, d.Name)
+		}
+	}
+	r.importPodSpec(app, template.Spec)
+	return app
+}
+
+func defaultRollingUpdate(ru *appsv1.RollingUpdateDeployment) bool {
+	def := intstr.FromString("25%")
+	return (ru.MaxUnavailable == nil || *ru.MaxUnavailable == def) &&
+		(ru.MaxSurge == nil || *ru.MaxSurge == def)
+}
+
+func (r *Result) importPodSpec(app *v1alpha1.Application, podSpec corev1.PodSpec) {
+	pod := podSpec.DeepCopy()
+	if len(pod.Containers) == 0 {
+		r.conflictf("Deployment %s has no container", app.Name)
+		return
+	}
+	mounted := r.importContainer(app, pod.Containers[0], pod.Volumes)
+	for _, c := range pod.Containers[1:] {
+		app.Spec.Sidecars = append(app.Spec.Sidecars, v1alpha1.ExtraContainer{Container: c.DeepCopy()})
+	}
+	for _, c := range pod.InitContainers {
+		app.Spec.InitContainers = append(app.Spec.InitContainers, v1alpha1.ExtraContainer{Container: c.DeepCopy()})
+	}
+	for _, v := range pod.Volumes {
+		if !mounted[v.Name] {
+			r.issuef("volume %s of Deployment %s is not represented", v.Name, app.Name)
+		}
+	}
+	app.Spec.NodeSelector = pod.NodeSelector
+	app.Spec.Tolerations = pod.Tolerations
+	app.Spec.Affinity = pod.Affinity
+	app.Spec.TopologySpreadConstraints = pod.TopologySpreadConstraints
+	app.Spec.ImagePullSecrets = pod.ImagePullSecrets
+	if name := pod.ServiceAccountName; name != "" && name != "default" || pod.AutomountServiceAccountToken != nil {
+		app.Spec.ServiceAccount = &v1alpha1.ServiceAccount{
+			Create:                       ptr.To(false),
+			AutomountServiceAccountToken: pod.AutomountServiceAccountToken,
+		}
+		if name != "" {
+			app.Spec.ServiceAccount.Name = name
+		}
+	}
+	r.importPodSecurity(app, pod.SecurityContext)
+
+	pod.Containers, pod.InitContainers, pod.Volumes = nil, nil, nil
+	pod.NodeSelector, pod.Tolerations, pod.Affinity, pod.TopologySpreadConstraints = nil, nil, nil, nil
+	pod.ImagePullSecrets = nil
+	pod.ServiceAccountName, pod.DeprecatedServiceAccount, pod.AutomountServiceAccountToken = "", "", nil
+	pod.SecurityContext = nil
+	// Defaulted by the API server.
+	if pod.RestartPolicy == corev1.RestartPolicyAlways {
+		pod.RestartPolicy = ""
+	}
+	if pod.DNSPolicy == corev1.DNSClusterFirst {
+		pod.DNSPolicy = ""
+	}
+	if pod.SchedulerName == corev1.DefaultSchedulerName {
+		pod.SchedulerName = ""
+	}
+	if ptr.Deref(pod.TerminationGracePeriodSeconds, 30) == 30 {
+		pod.TerminationGracePeriodSeconds = nil
+	}
+	r.unmapped("Deployment "+app.Name, "pod", pod)
+}
+
+// importContainer imports the application container, it returns the volumes
+// it mounts which are represented.
+func (r *Result) importContainer(app *v1alpha1.Application, c corev1.Container,
+	volumes []corev1.Volume) map[string]bool {
+	where := fmt.Sprintf("container %s of Deployment %s", c.Name, app.Name)
+	app.Spec.Image = c.Image
+	switch len(c.Command) {
+	case 0:
+	case 1:
+		app.Spec.StartCmd = c.Command[0]
+	default:
+		r.issuef("the command of %s has more than one element, only the first one is represented", where)
+		app.Spec.StartCmd = c.Command[0]
+	}
+	app.Spec.Args = c.Args
+	app.Spec.Env = c.Env
+	for _, from := range c.EnvFrom {
+		source := v1alpha1.ConfigFromSource{Prefix: from.Prefix}
+		switch {
+		case from.ConfigMapRef != nil:
+			source.ConfigMap = from.ConfigMapRef.Name
+			if ptr.Deref(from.ConfigMapRef.Optional, false) {
+				r.issuef("the optional envFrom ConfigMap %s of %s becomes required", source.ConfigMap, where)
+			}
+		case from.SecretRef != nil:
+			source.Secret = from.SecretRef.Name
+			if ptr.Deref(from.SecretRef.Optional, false) {
+				r.issuef("the optional envFrom Secret %s of %s becomes required", source.Secret, where)
+			}
+		}
+		app.Spec.ConfigFrom = append(app.Spec.ConfigFrom, source)
+	}
+	if len(c.Ports) > 0 {
+		app.Spec.Port = c.Ports[0].ContainerPort
+		if len(c.Ports) > 1 {
+			r.issuef("only the first port of %s is represented", where)
+		}
+	}
+
+	mounted := map[string]bool{}
+	for _, m := range c.VolumeMounts {
+		i := slices.IndexFunc(volumes, func(v corev1.Volume) bool { return v.Name == m.Name })
+		if i < 0 || m.SubPath != "" || m.SubPathExpr != "" || m.MountPropagation != nil {
+			r.issuef("volume mount %s of %s is not represented", m.MountPath, where)
+			continue
+		}
+		v := volumes[i]
+		volume := v1alpha1.ConfigVolume{Name: v.Name, MountPath: m.MountPath}
+		switch {
+		case v.ConfigMap != nil && len(v.ConfigMap.Items) == 0 && v.ConfigMap.Optional == nil:
+			volume.ConfigMap = v.ConfigMap.Name
+		case v.Secret != nil && len(v.Secret.Items) == 0 && v.Secret.Optional == nil:
+			volume.Secret = v.Secret.SecretName
+		default:
+			r.issuef("volume mount %s of %s is not represented", m.MountPath, where)
+			continue
+		}
+		if mounted[v.Name] {
+			r.issuef("volume %s is mounted more than once by %s, only once is represented", v.Name, where)
+			continue
+		}
+		mounted[v.Name] = true
+		app.Spec.Volumes = append(app.Spec.Volumes, volume)
+	}
+	r.importContainerSecurity(app, c.SecurityContext, where)
+
+	c.Name, c.Image, c.Command, c.Args, c.Env, c.EnvFrom, c.VolumeMounts = "", "", nil, nil, nil, nil, nil
+	c.SecurityContext = nil
+	if len(c.Ports) > 0 {
+		c.Ports = c.Ports[1:]
+		if len(c.Ports) == 0 {
+			c.Ports = nil
+		}
+	}
+	// IfNotPresent is the default of the operator.
+	if c.ImagePullPolicy != corev1.PullIfNotPresent {
+		app.Spec.ImagePullPolicy = c.ImagePullPolicy
+	}
+	c.ImagePullPolicy = ""
+	if c.TerminationMessagePath == corev1.TerminationMessagePathDefault {
+		c.TerminationMessagePath = ""
+	}
+	if c.TerminationMessagePolicy == corev1.TerminationMessageReadFile {
+		c.TerminationMessagePolicy = ""
+	}
+	r.unmapped(where, "", &c)
+	return mounted
+}
+
+func (r *Result) importPodSecurity(app *v1alpha1.Application, sc *corev1.PodSecurityContext) {
+	if sc == nil || equality.Semantic.DeepEqual(sc, &corev1.PodSecurityContext{}) {
+		return
+	}
+	security := security(app)
+	security.RunAsNonRoot = sc.RunAsNonRoot
+	security.RunAsUser = sc.RunAsUser
+	security.RunAsGroup = sc.RunAsGroup
+	security.FSGroup = sc.FSGroup
+	security.SeccompProfile = sc.SeccompProfile
+	rest := *sc
+	rest.RunAsNonRoot, rest.RunAsUser, rest.RunAsGroup, rest.FSGroup, rest.SeccompProfile = nil, nil, nil, nil, nil
+	r.unmapped("Deployment "+app.Name, "pod securityContext", &rest)
+}
+
+func (r *Result) importContainerSecurity(app *v1alpha1.Application, sc *corev1.SecurityContext, where string) {
+	if sc == nil || equality.Semantic.DeepEqual(sc, &corev1.SecurityContext{}) {
+		return
+	}
+	security := security(app)
+	security.AllowPrivilegeEscalation = sc.AllowPrivilegeEscalation
+	security.ReadOnlyRootFilesystem = sc.ReadOnlyRootFilesystem
+	security.Capabilities = sc.Capabilities
+	security.Privileged = sc.Privileged
+	rest := *sc
+	rest.AllowPrivilegeEscalation, rest.ReadOnlyRootFilesystem, rest.Capabilities, rest.Privileged = nil, nil, nil, nil
+	r.unmapped(where, "securityContext", &rest)
+}
+
+// security returns the security section of the application, with the
+// baseline profile so that only the imported settings apply.
+func security(app *v1alpha1.Application) *v1alpha1.Security {
+	if app.Spec.Security == nil {
+		app.Spec.Security = &v1alpha1.Security{Profile: v1alpha1.SecurityProfileBaseline}
+	}
+	return app.Spec.Security
+}
+
+func (r *Result) matchService(services []corev1.Service) *corev1.Service {
+	d := r.Deployment
+	podLabels := labels.Set(d.Spec.Template.Labels)
+	var match *corev1.Service
+	for i := range services {
+		s := &services[i]
+		if s.Namespace != d.Namespace || len(s.Spec.Selector) == 0 ||
+			!labels.SelectorFromSet(s.Spec.Selector).Matches(podLabels) {
+			continue
+		}
+		if match != nil {
+			r.issuef("Service %s also selects the pods of Deployment %s, it is not represented", s.Name, d.Name)
+			continue
+		}
+		match = s
+	}
+	return match
+}
+
+func (r *Result) matchIngress(ingresses []networkingv1.Ingress) *networkingv1.Ingress {
+	var match *networkingv1.Ingress
+	for i := range ingresses {
+		ing := &ingresses[i]
+		if ing.Namespace != r.Service.Namespace || !routesTo(ing, r.Service.Name) {
+			continue
+		}
+		if match != nil {
+			r.issuef("Ingress %s also routes to Service %s, it is not represented", ing.Name, r.Service.Name)
+			continue
+		}
+		match = ing
+	}
+	return match
+}
+
+func routesTo(ing *networkingv1.Ingress, service string) bool {
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			if p.Backend.Service != nil && p.Backend.Service.Name == service {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Result) importExpose() {
+	app := r.Application
+	svc := r.Service
+	if svc == nil {
+		r.conflictf("no Service exposes Deployment %s, the operator requires one", app.Name)
+		return
+	}
+	if svc.Name != app.Name {
+		r.conflictf("Service %s is not named after Deployment %s", svc.Name, app.Name)
+	}
+	if !maps.Equal(svc.Spec.Selector, map[string]string{"app": app.Name}) {
+		r.issuef("the selector of Service %s becomes app=%s", svc.Name, app.Name)
+	}
+	if len(svc.Spec.Ports) == 0 {
+		r.conflictf("Service %s has no port", svc.Name)
+		return
+	}
+	port := svc.Spec.Ports[0]
+	if len(svc.Spec.Ports) > 1 {
+		r.issuef("only the first port of Service %s is represented", svc.Name)
+	}
+	if app.Spec.Port == 0 {
+		// The port of the container is not declared, the Service targets it.
+		switch target := port.TargetPort; {
+		case target.Type == intstr.Int && target.IntVal != 0:
+			app.Spec.Port = target.IntVal
+		case target.Type == intstr.Int:
+			app.Spec.Port = port.Port
+		default:
+			r.conflictf("the container of Deployment %s has no port, the operator requires one", app.Name)
+		}
+	}
+	if target := port.TargetPort; target.IntValue() != int(app.Spec.Port) &&
+		!(target.Type == intstr.String && r.containerPortNamed(target.StrVal)) &&
+		!(target.Type == intstr.Int && target.IntVal == 0 && port.Port == app.Spec.Port) {
+		r.issuef("the target port of Service %s becomes the first port of the container", svc.Name)
+	}
+	app.Spec.Expose = &v1alpha1.Expose{ServicePort: port.Port}
+
+	rest := svc.Spec.DeepCopy()
+	rest.Ports, rest.Selector, rest.ClusterIP, rest.ClusterIPs = nil, nil, "", nil
+	rest.IPFamilies, rest.IPFamilyPolicy = nil, nil
+	if rest.SessionAffinity == corev1.ServiceAffinityNone {
+		rest.SessionAffinity = ""
+	}
+	if ptr.Deref(rest.InternalTrafficPolicy, corev1.ServiceInternalTrafficPolicyCluster) ==
+		corev1.ServiceInternalTrafficPolicyCluster {
+		rest.InternalTrafficPolicy = nil
+	}
+	if rest.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyCluster {
+		rest.ExternalTrafficPolicy = ""
+	}
+
+	switch {
+	case r.Ingress != nil:
+		rest.Type = ""
+		app.Spec.Expose.Mode = "Ingress"
+		r.importIngress()
+	case svc.Spec.Type == corev1.ServiceTypeNodePort:
+		rest.Type = ""
+		app.Spec.Expose.Mode = "NodePort"
+		app.Spec.Expose.NodePort = port.NodePort
+		if port.NodePort == 0 {
+			r.conflictf("Service %s has no node port, set spec.expose.nodePort", svc.Name)
+		}
+	default:
+		r.conflictf("Service %s of type %s is neither a NodePort Service nor exposed by an Ingress",
+			svc.Name, svc.Spec.Type)
+		return
+	}
+	if rest.Type == corev1.ServiceTypeClusterIP {
+		rest.Type = ""
+	}
+	r.unmapped("Service "+svc.Name, "spec", rest)
+}
+
+func (r *Result) containerPortNamed(name string) bool {
+	ports := r.Deployment.Spec.Template.Spec.Containers[0].Ports
+	return len(ports) > 0 && ports[0].Name == name
+}
+
+func (r *Result) importIngress() {
+	app := r.Application
+	ing := r.Ingress
+	if ing.Name != app.Name {
+		r.conflictf("Ingress %s is not named after Deployment %s", ing.Name, app.Name)
+	}
+	if className := ptr.Deref(ing.Spec.IngressClassName, ""); className != "nginx" {
+		r.issuef("the class of Ingress %s becomes nginx", ing.Name)
+	}
+	if len(ing.Annotations) > 0 {
+		r.issuef("the annotations of Ingress %s are not represented", ing.Name)
+	}
+	if len(ing.Spec.TLS) > 0 || ing.Spec.DefaultBackend != nil {
+		r.issuef("the TLS and default backend of Ingress %s are not represented", ing.Name)
+	}
+	rule := ing.Spec.Rules[0]
+	app.Spec.Expose.IngressDomain = rule.Host
+	if rule.Host == "" {
+		r.conflictf("the first rule of Ingress %s has no host, the operator requires one", ing.Name)
+	}
+	if len(ing.Spec.Rules) > 1 || rule.HTTP == nil || len(rule.HTTP.Paths) != 1 ||
+		rule.HTTP.Paths[0].Path != "/" || rule.HTTP.Paths[0].Backend.Service == nil ||
+		rule.HTTP.Paths[0].Backend.Service.Name != r.Service.Name {
+		r.issuef("the rules of Ingress %s become a single / prefix path to the Service", ing.Name)
+	}
+}
+
+// unmapped reports the fields of obj, a pointer to a struct, which are set.
+// The fields which are represented must have been cleared.
+func (r *Result) unmapped(where, what string, obj any) {
+	v := reflect.ValueOf(obj).Elem()
+	t := v.Type()
+	for i := range t.NumField() {
+		if v.Field(i).IsZero() {
+			continue
+		}
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if what != "" {
+			name = what + "." + name
+		}
+		r.issuef("%s of %s is not represented", name, where)
+	}
+}
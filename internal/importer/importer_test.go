@@ -0,0 +1,145 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/ptr"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+)
+
+func readObjects(t *testing.T, filename string) ([]appsv1.Deployment, []corev1.Service, []networkingv1.Ingress) {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close() //nolint:errcheck
+	var deployments []appsv1.Deployment
+	var services []corev1.Service
+	var ingresses []networkingv1.Ingress
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		var err error
+		switch u.GetKind() {
+		case "Deployment":
+			deployments = append(deployments, appsv1.Deployment{})
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &deployments[len(deployments)-1])
+		case "Service":
+			services = append(services, corev1.Service{})
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &services[len(services)-1])
+		case "Ingress":
+			ingresses = append(ingresses, networkingv1.Ingress{})
+			err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &ingresses[len(ingresses)-1])
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return deployments, services, ingresses
+}
+
+func TestImport(t *testing.T) {
+	results := Import(readObjects(t, "testdata/web.yaml"))
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	web := results[0]
+	want := &v1alpha1.Application{}
+	b, err := os.ReadFile("testdata/web_expect.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(b, want); err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(web.Application, want) {
+		t.Errorf("got Application %v, want %v", web.Application, want)
+	}
+	if web.Service == nil || web.Ingress == nil || len(web.Conflicts) != 0 {
+		t.Errorf("got Service %v, Ingress %v, conflicts %v", web.Service, web.Ingress, web.Conflicts)
+	}
+	wantIssues := []string{
+		"volume mount /var/cache/web of container web of Deployment web is not represented",
+		"resources of container web of Deployment web is not represented",
+		"readinessProbe of container web of Deployment web is not represented",
+		"volume cache of Deployment web is not represented",
+	}
+	if !slices.Equal(web.Issues, wantIssues) {
+		t.Errorf("got issues %q, want %q", web.Issues, wantIssues)
+	}
+
+	worker := results[1]
+	if worker.Application.Spec.Expose.Mode != "NodePort" || worker.Application.Spec.Expose.NodePort != 30090 ||
+		worker.Application.Spec.Port != 9090 {
+		t.Errorf("got port %d, expose %v", worker.Application.Spec.Port, worker.Application.Spec.Expose)
+	}
+	wantConflicts := []string{
+		"the selector of Deployment worker is not app=worker",
+		"Service worker-metrics is not named after Deployment worker",
+	}
+	if !slices.Equal(worker.Conflicts, wantConflicts) {
+		t.Errorf("got conflicts %q, want %q", worker.Conflicts, wantConflicts)
+	}
+	if !slices.Contains(worker.Issues, "spec.strategy of Deployment worker is not represented") {
+		t.Errorf("got issues %q", worker.Issues)
+	}
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	deployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop", Labels: map[string]string{"app": "api"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](2),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:            "api",
+						Image:           "registry.example.com/shop/api:2.0.1",
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Command:         []string{"/app/api"},
+						Args:            []string{"--listen", ":8080"},
+						Ports:           []corev1.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP}},
+						Env:             []corev1.EnvVar{{Name: "LOG_LEVEL", Value: "debug"}},
+						EnvFrom: []corev1.EnvFromSource{{
+							ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "api-env"}},
+						}},
+					}},
+				},
+			},
+		},
+	}
+	results := Import([]appsv1.Deployment{deployment}, nil, nil)
+	if len(results) != 1 || len(results[0].Issues) > 0 {
+		t.Fatalf("got results %v", results)
+	}
+	// Once adopted, the generated pod template must not roll the pods.
+	got := apps.NewDeployment(results[0].Application).Spec.Template
+	if !equality.Semantic.DeepEqual(got.Spec, deployment.Spec.Template.Spec) {
+		t.Errorf("got pod spec %v, want %v", got.Spec, deployment.Spec.Template.Spec)
+	}
+	if !equality.Semantic.DeepEqual(got.Labels, deployment.Spec.Template.Labels) {
+		t.Errorf("got pod labels %v, want %v", got.Labels, deployment.Spec.Template.Labels)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package overrides patches the resources generated for an Application with
+// the strategic merge patches and JSON patches of its overrides.
+package overrides
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+// protectedFields are the fields which identify a resource and the pods it
+// manages, overrides cannot change them.
+var protectedFields = [][]string{
+	{"metadata", "name"},
+	{"metadata", "namespace"},
+	{"metadata", "ownerReferences"},
+	{"spec", "selector"},
+	{"spec", "template", "metadata", "labels"},
+}
+
+// Apply applies the overrides of the kind to obj in order, and returns an
+// error if they change one of its protected fields or the security settings
+// and images of its pod template.
+func Apply[T any](obj *T, kind string, overrides []v1alpha1.Override) (*T, error) {
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	patched := original
+	for i, o := range overrides {
+		if o.Kind != kind {
+			continue
+		}
+		if o.Patch != nil {
+			if patched, err = strategicpatch.StrategicMergePatch(patched, o.Patch.Raw, obj); err != nil {
+				return nil, fmt.Errorf("invalid patch of override %d: %w", i, err)
+			}
+		}
+		if len(o.JSONPatch) > 0 {
+			ops, err := json.Marshal(o.JSONPatch)
+			if err != nil {
+				return nil, err
+			}
+			patch, err := jsonpatch.DecodePatch(ops)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jsonPatch of override %d: %w", i, err)
+			}
+			if patched, err = patch.Apply(patched); err != nil {
+				return nil, fmt.Errorf("unable to apply jsonPatch of override %d: %w", i, err)
+			}
+		}
+	}
+	if err := checkProtectedFields(original, patched); err != nil {
+		return nil, fmt.Errorf("%s overrides %w", kind, err)
+	}
+	if err := checkPodTemplate(original, patched); err != nil {
+		return nil, fmt.Errorf("%s overrides %w", kind, err)
+	}
+	result := new(T)
+	if err := json.Unmarshal(patched, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func checkProtectedFields(original, patched []byte) error {
+	before, after := map[string]any{}, map[string]any{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return err
+	}
+	for _, fields := range protectedFields {
+		b, _, _ := unstructured.NestedFieldNoCopy(before, fields...)
+		a, _, _ := unstructured.NestedFieldNoCopy(after, fields...)
+		if !equality.Semantic.DeepEqual(a, b) {
+			return fmt.Errorf("cannot change %s", strings.Join(fields, "."))
+		}
+	}
+	return nil
+}
+
+// Validate returns an error for every override which patches a protected
+// field, it does not need the patched resources.
+func Validate(overrides []v1alpha1.Override, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, o := range overrides {
+		if o.Patch != nil {
+			patch := map[string]any{}
+			if err := json.Unmarshal(o.Patch.Raw, &patch); err != nil {
+				errs = append(errs, field.Invalid(path.Index(i).Child("patch"), string(o.Patch.Raw), err.Error()))
+				continue
+			}
+			for _, fields := range protectedFields {
+				if _, found, _ := unstructured.NestedFieldNoCopy(patch, fields...); found {
+					errs = append(errs, field.Forbidden(path.Index(i).Child("patch"),
+						fmt.Sprintf("cannot change %s", strings.Join(fields, "."))))
+				}
+			}
+		}
+		for j, op := range o.JSONPatch {
+			pointers := []string{op.Path}
+			if op.Op == "move" {
+				pointers = append(pointers, op.From)
+			}
+			for _, pointer := range pointers {
+				if fields := protectedPointer(pointer); fields != "" {
+					errs = append(errs, field.Forbidden(path.Index(i).Child("jsonPatch").Index(j),
+						fmt.Sprintf("cannot change %s", fields)))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// protectedPointer returns the protected field a JSON pointer refers to or is
+// inside of, or an empty string.
+func protectedPointer(pointer string) string {
+	if pointer == "" {
+		return ""
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for _, fields := range protectedFields {
+		n := min(len(tokens), len(fields))
+		// A pointer to a parent such as /metadata replaces the protected field too.
+		if slices.Equal(tokens[:n], fields[:n]) {
+			return strings.Join(fields, ".")
+		}
+	}
+	return ""
+}
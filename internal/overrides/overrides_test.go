@@ -0,0 +1,124 @@
+package overrides
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func newDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "my-test"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "web", Image: "nginx", Env: []corev1.EnvVar{{Name: "A", Value: "1"}}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func raw(s string) *apiextensionsv1.JSON {
+	return &apiextensionsv1.JSON{Raw: []byte(s)}
+}
+
+func TestApply(t *testing.T) {
+	got, err := Apply(newDeployment(), "Deployment", []v1alpha1.Override{
+		{
+			Kind: "Deployment",
+			Patch: raw(`{"spec":{"template":{"metadata":{"annotations":{"team":"payments"}},"spec":{` +
+				`"hostAliases":[{"ip":"10.0.0.1","hostnames":["db.local"]}],` +
+				`"containers":[{"name":"web","env":[{"name":"B","value":"2"}]}]}}}}`),
+		},
+		{
+			Kind: "Deployment",
+			JSONPatch: []v1alpha1.JSONPatchOperation{
+				{Op: "add", Path: "/metadata/annotations", Value: raw(`{"team":"payments"}`)},
+			},
+		},
+		{
+			Kind:  "Service",
+			Patch: raw(`{"spec":{"type":"LoadBalancer"}}`),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := got.Spec.Template
+	if pod.Annotations["team"] != "payments" || pod.Labels["app"] != "web" || len(pod.Spec.HostAliases) != 1 {
+		t.Errorf("got pod template %v", pod)
+	}
+	// The containers and their env are merged by name.
+	if env := pod.Spec.Containers[0].Env; len(pod.Spec.Containers) != 1 || len(env) != 2 {
+		t.Errorf("got containers %v", pod.Spec.Containers)
+	}
+	if got.Annotations["team"] != "payments" {
+		t.Errorf("got annotations %v", got.Annotations)
+	}
+
+	for _, o := range []v1alpha1.Override{
+		{Kind: "Deployment", Patch: raw(`{"spec":{"selector":{"matchLabels":{"app":"other"}}}}`)},
+		{Kind: "Deployment", JSONPatch: []v1alpha1.JSONPatchOperation{
+			{Op: "replace", Path: "/metadata/name", Value: raw(`"other"`)},
+		}},
+		{Kind: "Deployment", JSONPatch: []v1alpha1.JSONPatchOperation{{Op: "remove", Path: "/spec/selector"}}},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"metadata":{"labels":{"app":"other"}}}}}`)},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"spec":{"hostNetwork":true}}}}`)},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"spec":{"securityContext":{"runAsUser":0}}}}}`)},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"spec":{` +
+			`"volumes":[{"name":"root","hostPath":{"path":"/"}}]}}}}`)},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"spec":{` +
+			`"containers":[{"name":"web","image":"evil.example.com/nginx"}]}}}}`)},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"spec":{` +
+			`"containers":[{"name":"web","securityContext":{"privileged":true}}]}}}}`)},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"spec":{` +
+			`"initContainers":[{"name":"setup","image":"busybox"}]}}}}`)},
+		{Kind: "Deployment", JSONPatch: []v1alpha1.JSONPatchOperation{
+			{Op: "replace", Path: "/spec/template/spec/containers/0/image", Value: raw(`"nginx:latest"`)},
+		}},
+	} {
+		if _, err := Apply(newDeployment(), "Deployment", []v1alpha1.Override{o}); err == nil {
+			t.Errorf("expected an error applying %v", o)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	overrides := []v1alpha1.Override{
+		{Kind: "Service", Patch: raw(`{"metadata":{"annotations":{"a":"b"}}}`)},
+		{Kind: "Service", Patch: raw(`{"spec":{"selector":{"app":"other"}}}`)},
+		{Kind: "Deployment", Patch: raw(`{"spec":{"template":{"metadata":{"labels":{"a":"b"}}}}}`)},
+		{Kind: "Deployment", JSONPatch: []v1alpha1.JSONPatchOperation{
+			{Op: "add", Path: "/spec/template/spec/hostNetwork", Value: raw(`true`)},
+			{Op: "replace", Path: "/metadata", Value: raw(`{}`)},
+			{Op: "move", From: "/metadata/namespace", Path: "/metadata/labels/ns"},
+			{Op: "copy", From: "/metadata/name", Path: "/metadata/labels/name"},
+		}},
+	}
+	errs := Validate(overrides, field.NewPath("spec", "overrides"))
+	want := []string{
+		"spec.overrides[1].patch",
+		"spec.overrides[2].patch",
+		"spec.overrides[3].jsonPatch[1]",
+		"spec.overrides[3].jsonPatch[2]",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got errors %v, want errors on %v", errs, want)
+	}
+	for i, err := range errs {
+		if err.Field != want[i] {
+			t.Errorf("got error on %s, want %s", err.Field, want[i])
+		}
+	}
+}
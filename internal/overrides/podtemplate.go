@@ -0,0 +1,81 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overrides
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// checkPodTemplate returns an error if the overrides change what the webhook
+// checks on the pod template of a workload against the privileged settings
+// gate and the image policy: the images and security contexts, the host
+// namespaces, the hostPath volumes and the set of containers.
+func checkPodTemplate(original, patched []byte) error {
+	var before, after struct {
+		Spec struct {
+			Template *corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return err
+	}
+	if before.Spec.Template == nil || after.Spec.Template == nil {
+		return nil
+	}
+	b, a := &before.Spec.Template.Spec, &after.Spec.Template.Spec
+	if a.HostNetwork != b.HostNetwork || a.HostPID != b.HostPID || a.HostIPC != b.HostIPC {
+		return fmt.Errorf("cannot change the host namespaces of the pod template")
+	}
+	if !equality.Semantic.DeepEqual(a.SecurityContext, b.SecurityContext) {
+		return fmt.Errorf("cannot change spec.template.spec.securityContext")
+	}
+	for _, v := range a.Volumes {
+		if v.HostPath != nil && !slices.ContainsFunc(b.Volumes, func(o corev1.Volume) bool {
+			return equality.Semantic.DeepEqual(o, v)
+		}) {
+			return fmt.Errorf("cannot add the hostPath volume %s", v.Name)
+		}
+	}
+	if err := checkContainers(b.InitContainers, a.InitContainers); err != nil {
+		return err
+	}
+	return checkContainers(b.Containers, a.Containers)
+}
+
+func checkContainers(before, after []corev1.Container) error {
+	for _, c := range after {
+		i := slices.IndexFunc(before, func(o corev1.Container) bool { return o.Name == c.Name })
+		if i < 0 {
+			return fmt.Errorf("cannot add the container %s", c.Name)
+		}
+		if c.Image != before[i].Image {
+			return fmt.Errorf("cannot change the image of container %s", c.Name)
+		}
+		if !equality.Semantic.DeepEqual(c.SecurityContext, before[i].SecurityContext) {
+			return fmt.Errorf("cannot change the securityContext of container %s", c.Name)
+		}
+	}
+	return nil
+}
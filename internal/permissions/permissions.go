@@ -0,0 +1,161 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package permissions verifies that the operator has been granted the RBAC
+// permissions its enabled features need.
+//
+// The generated ClusterRole is easily out of sync with a deployment which
+// restricts the operator to a few namespaces or edits its roles by hand, and
+// a missing permission otherwise only surfaces as forbidden errors in the
+// reconciliation of every Application. The Checker issues a
+// SelfSubjectAccessReview for each required permission and reports the missing
+// ones through the readiness probe.
+package permissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Permission is a verb on a resource the operator needs.
+type Permission struct {
+	Group    string
+	Resource string
+	Verb     string
+	// Namespace is the namespace the permission is needed in, the namespaces
+	// of the Checker are used when it is empty.
+	Namespace string
+	// ClusterScoped marks permissions on cluster scoped resources, which are
+	// never checked in a namespace.
+	ClusterScoped bool
+}
+
+// String returns the permission in the form of "verb group/resource in namespace".
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = p.Group + "/" + resource
+	}
+	switch {
+	case p.ClusterScoped:
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	case p.Namespace == "":
+		return fmt.Sprintf("%s %s in all namespaces", p.Verb, resource)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", p.Verb, resource, p.Namespace)
+}
+
+// Resource returns the permissions for every verb on the resources of a group.
+func Resource(group string, resources []string, verbs ...string) []Permission {
+	var permissions []Permission
+	for _, resource := range resources {
+		for _, verb := range verbs {
+			permissions = append(permissions, Permission{Group: group, Resource: resource, Verb: verb})
+		}
+	}
+	return permissions
+}
+
+// Checker verifies that the operator holds a set of permissions.
+type Checker struct {
+	client      client.Client
+	namespaces  []string
+	permissions []Permission
+
+	mu       sync.Mutex
+	verified bool
+}
+
+// NewChecker returns a Checker of the permissions. Permissions without a
+// namespace are checked in each of the namespaces, or in all namespaces if
+// none are given.
+func NewChecker(c client.Client, namespaces []string, permissions []Permission) *Checker {
+	return &Checker{client: c, namespaces: namespaces, permissions: permissions}
+}
+
+// Missing returns the permissions which have not been granted to the operator.
+func (c *Checker) Missing(ctx context.Context) ([]Permission, error) {
+	var missing []Permission
+	for _, p := range c.expand() {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: p.Namespace,
+					Verb:      p.Verb,
+					Group:     p.Group,
+					Resource:  p.Resource,
+				},
+			},
+		}
+		if err := c.client.Create(ctx, review); err != nil {
+			return nil, fmt.Errorf("unable to review permission to %s: %w", p, err)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+// Check implements healthz.Checker, it fails until every permission has been
+// granted. Once they all have been, they are not checked again.
+func (c *Checker) Check(req *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.verified {
+		return nil
+	}
+	missing, err := c.Missing(req.Context())
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return MissingError(missing)
+	}
+	c.verified = true
+	return nil
+}
+
+// MissingError returns an error listing the missing permissions.
+func MissingError(missing []Permission) error {
+	lines := make([]string, 0, len(missing))
+	for _, p := range missing {
+		lines = append(lines, p.String())
+	}
+	return errors.New("the operator is missing RBAC permissions to " + strings.Join(lines, ", "))
+}
+
+func (c *Checker) expand() []Permission {
+	var permissions []Permission
+	for _, p := range c.permissions {
+		if p.ClusterScoped || p.Namespace != "" || len(c.namespaces) == 0 {
+			permissions = append(permissions, p)
+			continue
+		}
+		for _, ns := range c.namespaces {
+			p.Namespace = ns
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
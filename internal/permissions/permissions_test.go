@@ -0,0 +1,93 @@
+package permissions
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newClient returns a client whose SelfSubjectAccessReviews are allowed when
+// granted reports so.
+func newClient(granted func(attrs *authorizationv1.ResourceAttributes) bool) client.Client {
+	return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			review := obj.(*authorizationv1.SelfSubjectAccessReview)
+			review.Status.Allowed = granted(review.Spec.ResourceAttributes)
+			return nil
+		},
+	}).Build()
+}
+
+func TestMissing(t *testing.T) {
+	required := append(Resource("apps", []string{"deployments"}, "get", "create"),
+		Permission{Resource: "namespaces", Verb: "get", ClusterScoped: true},
+		Permission{Group: "coordination.k8s.io", Resource: "leases", Verb: "get", Namespace: "system"})
+	tests := []struct {
+		name       string
+		namespaces []string
+		granted    func(attrs *authorizationv1.ResourceAttributes) bool
+		want       []string
+	}{
+		{
+			name:    "Test All Granted",
+			granted: func(attrs *authorizationv1.ResourceAttributes) bool { return true },
+		},
+		{
+			name: "Test Cluster Wide",
+			granted: func(attrs *authorizationv1.ResourceAttributes) bool {
+				return attrs.Verb != "create"
+			},
+			want: []string{"create apps/deployments in all namespaces"},
+		},
+		{
+			name:       "Test Watched Namespaces",
+			namespaces: []string{"team-a", "team-b"},
+			granted: func(attrs *authorizationv1.ResourceAttributes) bool {
+				return attrs.Namespace != "team-b" && attrs.Resource != "leases"
+			},
+			want: []string{
+				"get apps/deployments in namespace team-b",
+				"create apps/deployments in namespace team-b",
+				"get coordination.k8s.io/leases in namespace system",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, err := NewChecker(newClient(tt.granted), tt.namespaces, required).Missing(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range missing {
+				got = append(got, p.String())
+			}
+			if strings.Join(got, ";") != strings.Join(tt.want, ";") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	granted := false
+	checker := NewChecker(newClient(func(attrs *authorizationv1.ResourceAttributes) bool {
+		return granted
+	}), nil, Resource("", []string{"services"}, "get"))
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+	err := checker.Check(req)
+	if err == nil || !strings.Contains(err.Error(), "get services in all namespaces") {
+		t.Errorf("got %v, want missing permission error", err)
+	}
+	granted = true
+	if err := checker.Check(req); err != nil {
+		t.Errorf("got %v once granted", err)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Credentials authenticate the client to a registry.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// Keychain maps registry hosts, as named in image references, to their
+// credentials.
+type Keychain map[string]Credentials
+
+// keychainKey is the context key of the keychain set by WithKeychain.
+type keychainKey struct{}
+
+// WithKeychain returns a context whose lookups authenticate with the
+// credentials of the keychain. Registries absent from it are accessed
+// anonymously.
+func WithKeychain(ctx context.Context, keychain Keychain) context.Context {
+	return context.WithValue(ctx, keychainKey{}, keychain)
+}
+
+// credentials returns the credentials of the context for a registry.
+func credentials(ctx context.Context, registry string) (Credentials, bool) {
+	keychain, _ := ctx.Value(keychainKey{}).(Keychain)
+	creds, ok := keychain[registry]
+	return creds, ok
+}
+
+// ParseDockerConfig returns the keychain of the data of an image pull Secret,
+// either the .dockerconfigjson of a kubernetes.io/dockerconfigjson Secret or
+// the .dockercfg of a kubernetes.io/dockercfg one.
+func ParseDockerConfig(data []byte) (Keychain, error) {
+	type entry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	var config struct {
+		Auths map[string]entry `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid docker config: %w", err)
+	}
+	if config.Auths == nil {
+		// The legacy format has no auths key.
+		if err := json.Unmarshal(data, &config.Auths); err != nil {
+			return nil, fmt.Errorf("invalid docker config: %w", err)
+		}
+	}
+	keychain := Keychain{}
+	for server, e := range config.Auths {
+		creds := Credentials{Username: e.Username, Password: e.Password}
+		if e.Auth != "" {
+			auth, err := base64.StdEncoding.DecodeString(e.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth of %s: %w", server, err)
+			}
+			creds.Username, creds.Password, _ = strings.Cut(string(auth), ":")
+		}
+		keychain[registryHost(server)] = creds
+	}
+	return keychain, nil
+}
+
+// registryHost returns the registry host of a docker config server, which may
+// be a URL such as https://index.docker.io/v1/.
+func registryHost(server string) string {
+	if _, rest, ok := strings.Cut(server, "://"); ok {
+		server = rest
+	}
+	server, _, _ = strings.Cut(server, "/")
+	if server == "index.docker.io" || server == "registry-1.docker.io" {
+		return DefaultRegistry
+	}
+	return server
+}
@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/yanxinfire/application-management-operator/internal/registry/registrytest"
+)
+
+func TestParseDockerConfig(t *testing.T) {
+	want := Keychain{
+		DefaultRegistry:          {Username: "bot", Password: "secret"},
+		"registry.example.com":   {Username: "ci", Password: "p:ss"},
+		"registry.internal:5000": {Username: "dev", Password: "dev"},
+	}
+	for _, tt := range []struct {
+		name string
+		data string
+	}{
+		{
+			name: "dockerconfigjson",
+			data: `{"auths":{
+				"https://index.docker.io/v1/":{"username":"bot","password":"secret"},
+				"registry.example.com":{"auth":"Y2k6cDpzcw=="},
+				"http://registry.internal:5000":{"username":"dev","password":"dev"}}}`,
+		},
+		{
+			name: "dockercfg",
+			data: `{
+				"https://index.docker.io/v1/":{"username":"bot","password":"secret"},
+				"registry.example.com":{"auth":"Y2k6cDpzcw=="},
+				"http://registry.internal:5000":{"username":"dev","password":"dev"}}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDockerConfig([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+	if _, err := ParseDockerConfig([]byte(`{"auths":{"registry.example.com":{"auth":"%"}}}`)); err == nil {
+		t.Error("got no error for an invalid auth")
+	}
+}
+
+func TestClientCredentials(t *testing.T) {
+	reg := registrytest.NewRegistry(true)
+	defer reg.Close()
+	reg.RequireCredentials("ci", "secret")
+	want := reg.Push("team/app", "v1")
+	ref, err := ParseReference(reg.Host() + "/team/app:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	c := &Client{Insecure: []string{reg.Host()}}
+	if _, err := c.Digest(ctx, ref); err == nil {
+		t.Error("got no error without credentials")
+	}
+	ctx = WithKeychain(ctx, Keychain{reg.Host(): {Username: "ci", Password: "secret"}})
+	if got, err := c.Digest(ctx, ref); err != nil || got != want {
+		t.Errorf("got digest %s and error %v, want %s", got, err, want)
+	}
+
+	// The realm of the registry is reached over plain HTTP.
+	c.Insecure = nil
+	ref.Registry = "registry.example.com"
+	if _, err := c.authorize(WithKeychain(ctx, Keychain{ref.Registry: {Username: "ci"}}), ref,
+		`Bearer realm="`+reg.URL+`/token"`); err == nil {
+		t.Error("got no error sending credentials over plain HTTP")
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry is a minimal client of the OCI distribution API, it
+// resolves image tags to digests and lists the tags of repositories,
+// anonymously or with the credentials of image pull Secrets.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RequestTimeout is the timeout of the HTTP client of the operator, a single
+// request to a registry taking longer is abandoned.
+const RequestTimeout = 30 * time.Second
+
+// manifestTypes are the manifest media types accepted when resolving a tag,
+// indexes are preferred so that the digest is the same on every platform.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Resolver resolves the tag of an image to the digest of its manifest.
+type Resolver interface {
+	Digest(ctx context.Context, ref Reference) (string, error)
+}
+
+// TagLister lists the tags of the repository of an image.
+type TagLister interface {
+	Tags(ctx context.Context, ref Reference) ([]string, error)
+}
+
+// Client talks to registries over HTTPS, requesting bearer tokens when a
+// registry asks for them. It only contacts the registry of the image and the
+// token realm that registry names, callers restrict the registries of the
+// images they look up, for instance with the allowed registries of the image
+// policy. The credentials of the keychain of the context, if any, are sent to
+// the realm, never over plain HTTP unless the registry is insecure.
+type Client struct {
+	// HTTPClient sends the requests, http.DefaultClient is used when it is nil.
+	// It has no timeout, callers should bound the context of the lookups.
+	HTTPClient *http.Client
+	// Insecure are the registries reached over plain HTTP.
+	Insecure []string
+}
+
+var (
+	_ Resolver  = &Client{}
+	_ TagLister = &Client{}
+)
+
+// Digest returns the digest of the manifest the tag of ref points to, or the
+// digest of ref if it is already pinned.
+func (c *Client) Digest(ctx context.Context, ref Reference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	resp, err := c.do(ctx, http.MethodHead, ref, "manifests/"+ref.Tag, manifestTypes)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// The header is optional, the digest is computed from the manifest then.
+	resp, err = c.do(ctx, http.MethodGet, ref, "manifests/"+ref.Tag, manifestTypes)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, resp.Body); err != nil {
+		return "", fmt.Errorf("reading manifest of %s: %w", ref, err)
+	}
+	return fmt.Sprintf("sha256:%x", hash.Sum(nil)), nil
+}
+
+// Tags returns the tags of the repository of ref, following the pages of
+// the tag list.
+func (c *Client) Tags(ctx context.Context, ref Reference) ([]string, error) {
+	var tags []string
+	path := "tags/list"
+	for path != "" {
+		resp, err := c.do(ctx, http.MethodGet, ref, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag list of %s: %w", ref.Name(), err)
+		}
+		tags = append(tags, page.Tags...)
+
+		// The next page is linked as </v2/<repository>/tags/list?last=...>; rel="next".
+		path = ""
+		if link := resp.Header.Get("Link"); strings.Contains(link, `rel="next"`) {
+			if _, query, ok := strings.Cut(strings.Trim(strings.Split(link, ";")[0], " <>"), "?"); ok {
+				path = "tags/list?" + query
+			}
+		}
+	}
+	return tags, nil
+}
+
+// do sends a request for a path of the repository of ref, retrying it with a
+// bearer token when the registry requires one.
+func (c *Client) do(ctx context.Context, method string, ref Reference, path string,
+	accept []string) (*http.Response, error) {
+	scheme := "https"
+	if slices.Contains(c.Insecure, ref.Registry) {
+		scheme = "http"
+	}
+	host := ref.Registry
+	if host == DefaultRegistry {
+		host = "registry-1.docker.io"
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, host, ref.Repository, path)
+
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return c.httpClient().Do(req)
+	}
+	resp, err := send("")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+		authorization, err := c.authorize(ctx, ref, challenge)
+		if err != nil {
+			return nil, fmt.Errorf("authenticating to %s: %w", ref.Registry, err)
+		}
+		if resp, err = send(authorization); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: unexpected status %s", method, u, resp.Status)
+	}
+	return resp, nil
+}
+
+// authorize returns the Authorization header answering the challenge of the
+// registry of ref: the credentials of the context for a basic challenge, or a
+// token from the realm of a bearer challenge.
+func (c *Client) authorize(ctx context.Context, ref Reference, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	creds, ok := credentials(ctx, ref.Registry)
+	switch {
+	case strings.EqualFold(scheme, "Basic") && ok:
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Password)), nil
+	case !strings.EqualFold(scheme, "Bearer"):
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	var realm string
+	query := url.Values{}
+	for _, param := range splitParams(params) {
+		key, value, _ := strings.Cut(param, "=")
+		value = strings.Trim(value, `"`)
+		switch key = strings.TrimSpace(key); key {
+		case "realm":
+			realm = value
+		case "service", "scope":
+			query.Set(key, value)
+		}
+	}
+	if realm == "" {
+		return "", fmt.Errorf("authentication challenge %q has no realm", challenge)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		if req.URL.Scheme != "https" && !slices.Contains(c.Insecure, ref.Registry) {
+			return "", fmt.Errorf("refusing to send credentials to %s over plain HTTP", realm)
+		}
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	token, err := c.token(req, realm)
+	if err != nil {
+		return "", err
+	}
+	return "Bearer " + token, nil
+}
+
+// token sends a token request to the realm of a bearer challenge.
+func (c *Client) token(req *http.Request, realm string) (string, error) {
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request to %s: unexpected status %s", realm, resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid token response from %s: %w", realm, err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// splitParams splits the comma separated parameters of a challenge, commas
+// may appear in quoted values such as scopes.
+func splitParams(s string) []string {
+	var params []string
+	quoted, start := false, 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			params = append(params, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(params, s[start:])
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
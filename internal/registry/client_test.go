@@ -0,0 +1,71 @@
+package registry
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/yanxinfire/application-management-operator/internal/registry/registrytest"
+)
+
+func TestClientDigest(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		requireToken bool
+		omitDigest   bool
+	}{
+		{name: "anonymous"},
+		{name: "token", requireToken: true},
+		{name: "without digest header", omitDigest: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := registrytest.NewRegistry(tt.requireToken)
+			defer reg.Close()
+			if tt.omitDigest {
+				reg.OmitDigest()
+			}
+			want := reg.Push("team/app", "v1")
+			c := &Client{Insecure: []string{reg.Host()}}
+			ctx := context.Background()
+
+			ref, err := ParseReference(reg.Host() + "/team/app:v1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := c.Digest(ctx, ref); err != nil || got != want {
+				t.Errorf("Digest() = %s, %v, want %s", got, err, want)
+			}
+
+			ref.Tag = "v2"
+			if _, err := c.Digest(ctx, ref); err == nil {
+				t.Errorf("Digest() of a missing tag did not fail")
+			}
+		})
+	}
+}
+
+func TestClientTags(t *testing.T) {
+	reg := registrytest.NewRegistry(true)
+	defer reg.Close()
+	reg.Paginate(3)
+	want := []string{"1.4.0", "1.4.1", "1.5.0", "latest"}
+	for _, tag := range want {
+		reg.Push("team/app", tag)
+	}
+	c := &Client{Insecure: []string{reg.Host()}}
+	ctx := context.Background()
+
+	ref, err := ParseReference(reg.Host() + "/team/app:1.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Tags(ctx, ref)
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("Tags() = %v, %v, want %v", got, err, want)
+	}
+
+	ref.Repository = "team/missing"
+	if _, err := c.Tags(ctx, ref); err == nil {
+		t.Errorf("Tags() of a missing repository did not fail")
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRegistry is the registry of the images whose name has no registry.
+const DefaultRegistry = "docker.io"
+
+var (
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegexp        = regexp.MustCompile(`^\w[\w.-]{0,127}$`)
+	digestRegexp     = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+)
+
+// Reference is a parsed image reference.
+type Reference struct {
+	// Registry is the host of the registry, e.g. docker.io or localhost:5000.
+	Registry string
+	// Repository is the path of the repository in the registry, e.g. library/nginx.
+	Repository string
+	// Tag is the tag of the image, latest when the reference has neither a
+	// tag nor a digest.
+	Tag string
+	// Digest is the digest the reference is pinned to.
+	Digest string
+}
+
+// ParseReference parses an image reference, images without a registry are
+// normalized to docker.io and official images to the library repository.
+func ParseReference(image string) (Reference, error) {
+	var ref Reference
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+		if !digestRegexp.MatchString(ref.Digest) {
+			return Reference{}, fmt.Errorf("invalid digest in image %q", image)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+		if !tagRegexp.MatchString(ref.Tag) {
+			return Reference{}, fmt.Errorf("invalid tag in image %q", image)
+		}
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	ref.Registry, ref.Repository = DefaultRegistry, name
+	if i := strings.Index(name, "/"); i >= 0 {
+		if host := name[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry, ref.Repository = host, name[i+1:]
+		}
+	}
+	if ref.Registry == "index.docker.io" {
+		ref.Registry = DefaultRegistry
+	}
+	if ref.Registry == DefaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if !repositoryRegexp.MatchString(ref.Repository) {
+		return Reference{}, fmt.Errorf("invalid repository in image %q", image)
+	}
+	return ref, nil
+}
+
+// Name returns the registry and repository of the reference.
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the normalized reference.
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package registry
+
+import "testing"
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		image   string
+		want    string
+		wantErr bool
+	}{
+		{image: "nginx", want: "docker.io/library/nginx:latest"},
+		{image: "nginx:1.25", want: "docker.io/library/nginx:1.25"},
+		{image: "bitnami/redis:7", want: "docker.io/bitnami/redis:7"},
+		{image: "index.docker.io/library/nginx", want: "docker.io/library/nginx:latest"},
+		{image: "localhost/app:v1", want: "localhost/app:v1"},
+		{image: "localhost:5000/team/app", want: "localhost:5000/team/app:latest"},
+		{image: "ghcr.io/org/app@" + digest, want: "ghcr.io/org/app@" + digest},
+		{image: "ghcr.io/org/app:v2@" + digest, want: "ghcr.io/org/app:v2@" + digest},
+		{image: "Nginx", wantErr: true},
+		{image: "nginx:", wantErr: true},
+		{image: "nginx@sha256:abc", wantErr: true},
+		{image: "registry.example.com/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			ref, err := ParseReference(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && ref.String() != tt.want {
+				t.Errorf("ParseReference() = %s, want %s", ref, tt.want)
+			}
+		})
+	}
+}
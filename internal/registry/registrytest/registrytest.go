@@ -0,0 +1,194 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registrytest provides an in-process registry serving the parts of
+// the OCI distribution API used by the registry package.
+package registrytest
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	manifestType = "application/vnd.oci.image.manifest.v1+json"
+	testToken    = "registrytest-token"
+)
+
+// Registry is an in-process registry holding pushed manifests in memory.
+type Registry struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// manifests maps repositories to their manifests by tag.
+	manifests map[string]map[string][]byte
+	// pushes counts the pushes so that every manifest is unique.
+	pushes int
+	// requireToken makes the registry ask for a bearer token from its realm.
+	requireToken bool
+	// omitDigest makes the registry leave the digest header out of responses.
+	omitDigest bool
+	// pageSize is the maximum number of tags listed per page, zero for no limit.
+	pageSize int
+	// username and password are required by the realm to issue tokens when
+	// username is set.
+	username, password string
+}
+
+// NewRegistry starts a registry, requiring bearer tokens if
+// requireToken is true. It must be closed by the caller.
+func NewRegistry(requireToken bool) *Registry {
+	r := &Registry{manifests: map[string]map[string][]byte{}, requireToken: requireToken}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// Host returns the host of the registry, images of the registry are named
+// after it.
+func (r *Registry) Host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+// OmitDigest makes the registry leave the Docker-Content-Digest header out of
+// its responses.
+func (r *Registry) OmitDigest() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.omitDigest = true
+}
+
+// RequireCredentials makes the realm of the registry issue tokens only to
+// requests authenticated with the username and password.
+func (r *Registry) RequireCredentials(username, password string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.username, r.password = username, password
+}
+
+// Paginate makes the registry list at most size tags per page when the
+// request does not set a smaller page size.
+func (r *Registry) Paginate(size int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pageSize = size
+}
+
+// Push stores a new manifest for the tag of a repository and returns its
+// digest, pushing the same tag again moves it to a new manifest.
+func (r *Registry) Push(repository, tag string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tags := r.manifests[repository]
+	if tags == nil {
+		tags = map[string][]byte{}
+		r.manifests[repository] = tags
+	}
+	r.pushes++
+	tags[tag] = fmt.Appendf(nil, `{"schemaVersion":2,"mediaType":%q,"annotations":{"tag":%q,"push":"%d"}}`,
+		manifestType, repository+":"+tag, r.pushes)
+	return digest(tags[tag])
+}
+
+func (r *Registry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if req.URL.Path == "/token" {
+		if username, password, _ := req.BasicAuth(); username != r.username || password != r.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"token":%q}`, testToken)
+		return
+	}
+	if r.requireToken && req.Header.Get("Authorization") != "Bearer "+testToken {
+		w.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="registrytest",scope="repository:pull"`, r.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path, ok := strings.CutPrefix(req.URL.Path, "/v2/")
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	if repository, reference, ok := strings.Cut(path, "/manifests/"); ok {
+		r.serveManifest(w, req, repository, reference)
+		return
+	}
+	if repository, ok := strings.CutSuffix(path, "/tags/list"); ok {
+		r.serveTags(w, req, repository)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+// serveTags serves the sorted tags of a repository, paginated when the
+// request has the n parameter or the registry limits the page size.
+func (r *Registry) serveTags(w http.ResponseWriter, req *http.Request, repository string) {
+	tags, ok := r.manifests[repository]
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	page := slices.Sorted(maps.Keys(tags))
+	if last := req.URL.Query().Get("last"); last != "" {
+		i, _ := slices.BinarySearch(page, last)
+		for i < len(page) && page[i] <= last {
+			i++
+		}
+		page = page[i:]
+	}
+	n, err := strconv.Atoi(req.URL.Query().Get("n"))
+	if err != nil || (r.pageSize > 0 && n > r.pageSize) {
+		n = r.pageSize
+	}
+	if n > 0 && n < len(page) {
+		page = page[:n]
+		w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?last=%s&n=%d>; rel="next"`,
+			repository, page[len(page)-1], n))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"name": repository, "tags": page})
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, repository, reference string) {
+	for tag, manifest := range r.manifests[repository] {
+		if tag != reference && digest(manifest) != reference {
+			continue
+		}
+		w.Header().Set("Content-Type", manifestType)
+		if !r.omitDigest {
+			w.Header().Set("Docker-Content-Digest", digest(manifest))
+		}
+		if req.Method != http.MethodHead {
+			_, _ = w.Write(manifest)
+		}
+		return
+	}
+	http.NotFound(w, req)
+}
+
+func digest(manifest []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+}
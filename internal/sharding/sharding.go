@@ -0,0 +1,284 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharding splits Applications across several operator replicas.
+//
+// Every replica holds a Lease labelled as a shard member in the operator
+// namespace and renews it periodically. The live members are derived from the
+// Leases which have not expired, and each Application is assigned to exactly
+// one member using rendezvous hashing, so only the Applications of a member
+// which joins or leaves move to another replica.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/permissions"
+)
+
+const (
+	// MemberLabel marks the Leases held by shard members.
+	MemberLabel = "apps.xinyan.cn/shard-member"
+	// ShardLabel pins an Application to a shard. All Applications with the same
+	// value are handled by the same replica, and a value equal to a member ID
+	// assigns them to that member while it is alive.
+	ShardLabel = "apps.xinyan.cn/shard"
+
+	leasePrefix = "application-shard-"
+)
+
+// Coordinator maintains the shard membership of one operator replica.
+// It is a manager.Runnable and must run on every replica, independently of
+// leader election.
+type Coordinator struct {
+	// Client is used to write the Lease and to list Applications on rebalance.
+	Client client.Client
+	// Reader is used to read the member Leases, usually the uncached API reader.
+	Reader client.Reader
+	// Namespace holds the member Leases.
+	Namespace string
+	// ID identifies this replica, it must be unique among the members.
+	ID string
+	// LeaseDuration is how long a member is considered alive without renewal.
+	LeaseDuration time.Duration
+
+	mu      sync.RWMutex
+	members []string
+	events  chan event.GenericEvent
+	logger  logr.Logger
+}
+
+// RequiredPermissions returns the permissions a Coordinator needs on the
+// member Leases in the namespace.
+func RequiredPermissions(namespace string) []permissions.Permission {
+	required := permissions.Resource(coordinationv1.GroupName, []string{"leases"},
+		"get", "list", "watch", "create", "update", "delete")
+	for i := range required {
+		required[i].Namespace = namespace
+	}
+	return required
+}
+
+// NewCoordinator returns a Coordinator for the replica with the given ID.
+func NewCoordinator(c client.Client, reader client.Reader, namespace, id string,
+	leaseDuration time.Duration) *Coordinator {
+	return &Coordinator{
+		Client:        c,
+		Reader:        reader,
+		Namespace:     namespace,
+		ID:            id,
+		LeaseDuration: leaseDuration,
+		events:        make(chan event.GenericEvent, 1024),
+	}
+}
+
+// Events returns the channel on which Applications that moved to this replica
+// are sent after a rebalance.
+func (c *Coordinator) Events() <-chan event.GenericEvent {
+	return c.events
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, every replica
+// must take part in the membership.
+func (c *Coordinator) NeedLeaderElection() bool {
+	return false
+}
+
+// Start renews the Lease of this replica until the context is cancelled, and
+// releases it on shutdown so that the other members take over immediately.
+func (c *Coordinator) Start(ctx context.Context) error {
+	c.logger = logf.FromContext(ctx).WithName("sharding").WithValues("member", c.ID)
+	renew := c.LeaseDuration / 3
+	ticker := time.NewTicker(renew)
+	defer ticker.Stop()
+	for {
+		if err := c.sync(ctx); err != nil {
+			c.logger.Error(err, "unable to sync shard membership")
+		}
+		select {
+		case <-ctx.Done():
+			c.release()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Members returns the IDs of the live members, sorted.
+func (c *Coordinator) Members() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.members)
+}
+
+// Owns reports whether the object belongs to the shard of this replica.
+// Nothing is owned until the membership has been established.
+func (c *Coordinator) Owns(obj client.Object) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Assign(obj, c.members) == c.ID
+}
+
+// Predicate filters out the events of Applications owned by other replicas.
+func (c *Coordinator) Predicate() predicate.Predicate {
+	return predicate.NewPredicateFuncs(c.Owns)
+}
+
+// Assign returns the member responsible for the object, or an empty string if
+// there are no members.
+func Assign(obj client.Object, members []string) string {
+	if len(members) == 0 {
+		return ""
+	}
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}.String()
+	if shard, ok := obj.GetLabels()[ShardLabel]; ok && shard != "" {
+		if slices.Contains(members, shard) {
+			return shard
+		}
+		key = shard
+	}
+	var owner string
+	var best uint64
+	for _, m := range members {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(m))
+		if score := h.Sum64(); owner == "" || score > best {
+			owner, best = m, score
+		}
+	}
+	return owner
+}
+
+func (c *Coordinator) sync(ctx context.Context) error {
+	if err := c.renew(ctx); err != nil {
+		return err
+	}
+	leases := &coordinationv1.LeaseList{}
+	if err := c.Reader.List(ctx, leases, client.InNamespace(c.Namespace),
+		client.MatchingLabels{MemberLabel: "true"}); err != nil {
+		return err
+	}
+	members := liveMembers(leases.Items, time.Now())
+	c.mu.Lock()
+	changed := !slices.Equal(members, c.members)
+	c.members = members
+	c.mu.Unlock()
+	if changed {
+		c.logger.Info("Shard membership changed", "members", members)
+		return c.rebalance(ctx)
+	}
+	return nil
+}
+
+func (c *Coordinator) renew(ctx context.Context) error {
+	now := metav1.NewMicroTime(time.Now())
+	lease := &coordinationv1.Lease{}
+	err := c.Reader.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: leasePrefix + c.ID}, lease)
+	if errors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      leasePrefix + c.ID,
+				Namespace: c.Namespace,
+				Labels:    map[string]string{MemberLabel: "true"},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       ptr.To(c.ID),
+				LeaseDurationSeconds: ptr.To(int32(c.LeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		return c.Client.Create(ctx, lease)
+	}
+	if err != nil {
+		return err
+	}
+	if holder := ptr.Deref(lease.Spec.HolderIdentity, ""); holder != c.ID {
+		return fmt.Errorf("lease %s is held by %q, shard IDs must be unique", lease.Name, holder)
+	}
+	lease.Spec.LeaseDurationSeconds = ptr.To(int32(c.LeaseDuration.Seconds()))
+	lease.Spec.RenewTime = &now
+	return c.Client.Update(ctx, lease)
+}
+
+// release deletes the Lease of this replica, it runs after the manager context
+// has been cancelled and therefore uses its own timeout.
+func (c *Coordinator) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{
+		Name:      leasePrefix + c.ID,
+		Namespace: c.Namespace,
+	}}
+	if err := c.Client.Delete(ctx, lease); client.IgnoreNotFound(err) != nil {
+		c.logger.Error(err, "unable to release shard lease")
+	}
+}
+
+// rebalance enqueues every Application owned by this replica, since some of
+// them may have been handled by another replica until now.
+func (c *Coordinator) rebalance(ctx context.Context) error {
+	apps := &appsv1alpha1.ApplicationList{}
+	if err := c.Client.List(ctx, apps); err != nil {
+		return err
+	}
+	for i := range apps.Items {
+		if !c.Owns(&apps.Items[i]) {
+			continue
+		}
+		select {
+		case c.events <- event.GenericEvent{Object: &apps.Items[i]}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	return nil
+}
+
+func liveMembers(leases []coordinationv1.Lease, now time.Time) []string {
+	members := []string{}
+	for _, lease := range leases {
+		spec := lease.Spec
+		if spec.HolderIdentity == nil || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		expiry := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second)
+		if now.Before(expiry) {
+			members = append(members, *spec.HolderIdentity)
+		}
+	}
+	slices.Sort(members)
+	return slices.Compact(members)
+}
@@ -0,0 +1,154 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+)
+
+func newApplication(name string, labels map[string]string) *v1alpha1.Application {
+	return &v1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "my-test", Labels: labels},
+	}
+}
+
+func TestAssign(t *testing.T) {
+	members := []string{"a", "b", "c"}
+	counts := map[string]int{}
+	moved := 0
+	for i := range 300 {
+		app := newApplication(fmt.Sprintf("app-%d", i), nil)
+		owner := Assign(app, members)
+		counts[owner]++
+		if owner != Assign(app, members) {
+			t.Fatalf("assignment of %s is not stable", app.Name)
+		}
+		if after := Assign(app, []string{"a", "b"}); owner != "c" && after != owner {
+			moved++
+		}
+	}
+	for _, m := range members {
+		if counts[m] < 50 {
+			t.Errorf("member %s owns only %d of 300 applications", m, counts[m])
+		}
+	}
+	if moved != 0 {
+		t.Errorf("%d applications moved between remaining members", moved)
+	}
+
+	if got := Assign(newApplication("x", nil), nil); got != "" {
+		t.Errorf("got owner %q without members", got)
+	}
+	if got := Assign(newApplication("x", map[string]string{ShardLabel: "b"}), members); got != "b" {
+		t.Errorf("got owner %q, want pinned member b", got)
+	}
+	group := map[string]string{ShardLabel: "payments"}
+	if Assign(newApplication("x", group), members) != Assign(newApplication("y", group), members) {
+		t.Errorf("applications of the same shard have different owners")
+	}
+}
+
+func TestCoordinatorSync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	expired := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	stale := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leasePrefix + "gone",
+			Namespace: "system",
+			Labels:    map[string]string{MemberLabel: "true"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To("gone"),
+			LeaseDurationSeconds: ptr.To(int32(15)),
+			RenewTime:            &expired,
+		},
+	}
+	app := newApplication("my-app", nil)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stale, app).Build()
+	coordinator := NewCoordinator(c, c, "system", "me", 15*time.Second)
+	ctx := context.Background()
+
+	if coordinator.Owns(app) {
+		t.Fatalf("application is owned before the membership is established")
+	}
+	if err := coordinator.sync(ctx); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := coordinator.Members(); len(got) != 1 || got[0] != "me" {
+		t.Errorf("got members %v, want [me]", got)
+	}
+	if !coordinator.Owns(app) {
+		t.Errorf("single member does not own the application")
+	}
+	select {
+	case e := <-coordinator.Events():
+		if e.Object.GetName() != app.Name {
+			t.Errorf("got rebalance event for %s", e.Object.GetName())
+		}
+	default:
+		t.Errorf("no rebalance event after the membership changed")
+	}
+	if err := coordinator.sync(ctx); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	select {
+	case <-coordinator.Events():
+		t.Errorf("rebalance event without membership change")
+	default:
+	}
+}
+
+func TestCoordinatorRenewReadsThroughReader(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	renewed := metav1.NewMicroTime(time.Now().Add(-5 * time.Second))
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      leasePrefix + "me",
+			Namespace: "system",
+			Labels:    map[string]string{MemberLabel: "true"},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       ptr.To("me"),
+			LeaseDurationSeconds: ptr.To(int32(15)),
+			RenewTime:            &renewed,
+		},
+	}
+	api := fake.NewClientBuilder().WithScheme(scheme).WithObjects(lease).Build()
+	// The cache has not seen the Lease yet, reading it there would make renew
+	// create it again.
+	cached := interceptor.NewClient(api, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+			opts ...client.GetOption) error {
+			return errors.NewNotFound(coordinationv1.Resource("leases"), key.Name)
+		},
+	})
+	coordinator := NewCoordinator(cached, api, "system", "me", 15*time.Second)
+	if err := coordinator.renew(context.Background()); err != nil {
+		t.Fatalf("renew failed: %v", err)
+	}
+	got := &coordinationv1.Lease{}
+	if err := api.Get(context.Background(), client.ObjectKeyFromObject(lease), got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Spec.RenewTime.After(renewed.Time) {
+		t.Errorf("lease was not renewed, renew time %v", got.Spec.RenewTime)
+	}
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2025 Xin Yan.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/blang/semver/v4"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/controller/apps"
+	"github.com/yanxinfire/application-management-operator/internal/imagepolicy"
+	"github.com/yanxinfire/application-management-operator/internal/overrides"
+	"github.com/yanxinfire/application-management-operator/internal/permissions"
+)
+
+// AllowPrivilegedLabel must be set to "true" on a namespace for its
+// Applications to use privileged security settings.
+const AllowPrivilegedLabel = "apps.xinyan.cn/allow-privileged"
+
+// nolint:unused
+// log is for logging in this package.
+var applicationlog = logf.Log.WithName("application-resource")
+
+// SetupApplicationWebhookWithManager registers the webhook for Application in the manager,
+// the images of Applications are checked against the image policy unless it is nil.
+func SetupApplicationWebhookWithManager(mgr ctrl.Manager, imagePolicy *imagepolicy.Policy) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&appsv1alpha1.Application{}).
+		WithValidator(&ApplicationCustomValidator{Client: mgr.GetClient(), ImagePolicy: imagePolicy}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-apps-xinyan-cn-v1alpha1-application,mutating=false,failurePolicy=fail,sideEffects=None,groups=apps.xinyan.cn,resources=applications,verbs=create;update,versions=v1alpha1,name=vapplication-v1alpha1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// RequiredPermissions returns the permissions the webhook needs, they must be
+// kept in sync with the rbac markers above.
+func RequiredPermissions() []permissions.Permission {
+	required := permissions.Resource("", []string{"namespaces"}, "get", "list", "watch")
+	required = append(required, permissions.Permission{
+		Group: authorizationv1.GroupName, Resource: "subjectaccessreviews", Verb: "create",
+	})
+	for i := range required {
+		required[i].ClusterScoped = true
+	}
+	return required
+}
+
+// ApplicationCustomValidator struct is responsible for validating the Application resource
+// when it is created, updated, or deleted.
+type ApplicationCustomValidator struct {
+	Client client.Client
+	// ImagePolicy restricts the images of Applications, any image is allowed
+	// when it is nil.
+	ImagePolicy *imagepolicy.Policy
+}
+
+var _ webhook.CustomValidator = &ApplicationCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Application.
+func (v *ApplicationCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	application, ok := obj.(*appsv1alpha1.Application)
+	if !ok {
+		return nil, fmt.Errorf("expected a Application object but got %T", obj)
+	}
+	applicationlog.Info("Validation for Application upon creation", "name", application.GetName())
+
+	return nil, v.validateApplication(ctx, application, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Application.
+func (v *ApplicationCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	application, ok := newObj.(*appsv1alpha1.Application)
+	if !ok {
+		return nil, fmt.Errorf("expected a Application object for the newObj but got %T", newObj)
+	}
+	oldApplication, ok := oldObj.(*appsv1alpha1.Application)
+	if !ok {
+		return nil, fmt.Errorf("expected a Application object for the oldObj but got %T", oldObj)
+	}
+	applicationlog.Info("Validation for Application upon update", "name", application.GetName())
+
+	return nil, v.validateApplication(ctx, application, oldApplication)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Application.
+func (v *ApplicationCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ApplicationCustomValidator) validateApplication(ctx context.Context,
+	app, oldApp *appsv1alpha1.Application) error {
+	var allErrs field.ErrorList
+	privileged := privilegedSettings(app.Spec.Security, field.NewPath("spec", "security"))
+	for _, extra := range []struct {
+		name       string
+		containers []appsv1alpha1.ExtraContainer
+	}{
+		{"initContainers", app.Spec.InitContainers},
+		{"sidecars", app.Spec.Sidecars},
+	} {
+		for i, c := range extra.containers {
+			if c.Container != nil {
+				privileged = append(privileged, containerPrivilegedSettings(c.Container.SecurityContext,
+					field.NewPath("spec", extra.name).Index(i).Child("container", "securityContext"))...)
+			}
+		}
+	}
+	if len(privileged) > 0 {
+		allowed, err := v.privilegedAllowed(ctx, app.Namespace)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			allErrs = append(allErrs, privileged...)
+		}
+	}
+	allErrs = append(allErrs, v.ImagePolicy.Validate(app)...)
+	allErrs = append(allErrs, validateImageUpdate(app.Spec.ImageUpdate, field.NewPath("spec", "imageUpdate"))...)
+	if errs := overrides.Validate(app.Spec.Overrides, field.NewPath("spec", "overrides")); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	} else {
+		allErrs = append(allErrs, validateWorkloadOverrides(app, field.NewPath("spec", "overrides"))...)
+	}
+	roleErrs, err := v.validateRoles(ctx, app, oldApp)
+	if err != nil {
+		return err
+	}
+	allErrs = append(allErrs, roleErrs...)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(appsv1alpha1.GroupVersion.WithKind("Application").GroupKind(),
+		app.Name, allErrs)
+}
+
+// validateWorkloadOverrides applies the overrides to the workload of the
+// application, which fails if they change the security settings or the images
+// of its pod template and so escape the checks above.
+func validateWorkloadOverrides(app *appsv1alpha1.Application, path *field.Path) field.ErrorList {
+	if len(app.Spec.Overrides) == 0 {
+		return nil
+	}
+	var err error
+	switch app.Spec.WorkloadKind {
+	case appsv1alpha1.WorkloadKindStatefulSet:
+		_, err = overrides.Apply(apps.NewStatefulSet(app), appsv1alpha1.WorkloadKindStatefulSet, app.Spec.Overrides)
+	case appsv1alpha1.WorkloadKindDaemonSet:
+		_, err = overrides.Apply(apps.NewDaemonSet(app), appsv1alpha1.WorkloadKindDaemonSet, app.Spec.Overrides)
+	default:
+		_, err = overrides.Apply(apps.NewDeployment(app), appsv1alpha1.WorkloadKindDeployment, app.Spec.Overrides)
+	}
+	if err != nil {
+		return field.ErrorList{field.Forbidden(path, err.Error())}
+	}
+	return nil
+}
+
+// validateImageUpdate checks that the semver range and the tag filter of an
+// image update policy parse.
+func validateImageUpdate(update *appsv1alpha1.ImageUpdate, path *field.Path) field.ErrorList {
+	if update == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if _, err := semver.ParseRange(update.Semver); err != nil {
+		errs = append(errs, field.Invalid(path.Child("semver"), update.Semver, err.Error()))
+	}
+	if _, err := regexp.Compile(update.Filter); err != nil {
+		errs = append(errs, field.Invalid(path.Child("filter"), update.Filter, err.Error()))
+	}
+	return errs
+}
+
+// privilegedAllowed reports whether the namespace is labelled to allow
+// privileged security settings.
+func (v *ApplicationCustomValidator) privilegedAllowed(ctx context.Context, namespace string) (bool, error) {
+	ns := &corev1.Namespace{}
+	if err := v.Client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	return ns.Labels[AllowPrivilegedLabel] == "true", nil
+}
+
+// validateRoles forbids binding roles to the application's ServiceAccount
+// which the requesting user is not allowed to bind, so that the operator's own
+// bind permission cannot be used to escalate privileges. Roles which were
+// already bound before an update are not checked again.
+func (v *ApplicationCustomValidator) validateRoles(ctx context.Context,
+	app, oldApp *appsv1alpha1.Application) (field.ErrorList, error) {
+	if app.Spec.ServiceAccount == nil || len(app.Spec.ServiceAccount.Roles) == 0 {
+		return nil, nil
+	}
+	var bound []appsv1alpha1.RoleRef
+	if oldApp != nil && oldApp.Spec.ServiceAccount != nil {
+		bound = oldApp.Spec.ServiceAccount.Roles
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var errs field.ErrorList
+	path := field.NewPath("spec", "serviceAccount", "roles")
+	for i, role := range app.Spec.ServiceAccount.Roles {
+		if slices.Contains(bound, role) {
+			continue
+		}
+		resource := "roles"
+		if role.Kind == "ClusterRole" {
+			resource = "clusterroles"
+		}
+		extra := map[string]authorizationv1.ExtraValue{}
+		for k, values := range req.UserInfo.Extra {
+			extra[k] = authorizationv1.ExtraValue(values)
+		}
+		review := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   req.UserInfo.Username,
+				UID:    req.UserInfo.UID,
+				Groups: req.UserInfo.Groups,
+				Extra:  extra,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: app.Namespace,
+					Verb:      "bind",
+					Group:     rbacv1.GroupName,
+					Resource:  resource,
+					Name:      role.Name,
+				},
+			},
+		}
+		if err := v.Client.Create(ctx, review); err != nil {
+			return nil, err
+		}
+		if !review.Status.Allowed {
+			errs = append(errs, field.Forbidden(path.Index(i),
+				fmt.Sprintf("user %s is not allowed to bind %s %s", req.UserInfo.Username, role.Kind, role.Name)))
+		}
+	}
+	return errs, nil
+}
+
+// containerPrivilegedSettings is privilegedSettings for the security context
+// of an init container or a sidecar.
+func containerPrivilegedSettings(sc *corev1.SecurityContext, path *field.Path) field.ErrorList {
+	if sc == nil {
+		return nil
+	}
+	return privilegedSettings(&appsv1alpha1.Security{
+		Privileged:               sc.Privileged,
+		AllowPrivilegeEscalation: sc.AllowPrivilegeEscalation,
+		RunAsUser:                sc.RunAsUser,
+		Capabilities:             sc.Capabilities,
+		SeccompProfile:           sc.SeccompProfile,
+	}, path)
+}
+
+// privilegedSettings returns an error for every security setting which
+// weakens the isolation of the pods beyond the baseline Pod Security Standard.
+func privilegedSettings(security *appsv1alpha1.Security, path *field.Path) field.ErrorList {
+	if security == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	reason := fmt.Sprintf("privileged settings require the namespace label %s=true", AllowPrivilegedLabel)
+	if ptr.Deref(security.Privileged, false) {
+		errs = append(errs, field.Forbidden(path.Child("privileged"), reason))
+	}
+	if ptr.Deref(security.AllowPrivilegeEscalation, false) {
+		errs = append(errs, field.Forbidden(path.Child("allowPrivilegeEscalation"), reason))
+	}
+	if security.RunAsUser != nil && *security.RunAsUser == 0 {
+		errs = append(errs, field.Forbidden(path.Child("runAsUser"), reason))
+	}
+	if security.Capabilities != nil {
+		for i, c := range security.Capabilities.Add {
+			if c != "NET_BIND_SERVICE" {
+				errs = append(errs, field.Forbidden(path.Child("capabilities", "add").Index(i), reason))
+			}
+		}
+	}
+	if security.SeccompProfile != nil && security.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		errs = append(errs, field.Forbidden(path.Child("seccompProfile", "type"), reason))
+	}
+	return errs
+}
@@ -0,0 +1,214 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/yanxinfire/application-management-operator/api/apps/v1alpha1"
+	"github.com/yanxinfire/application-management-operator/internal/imagepolicy"
+)
+
+func newApplication(namespace string, security *appsv1alpha1.Security) *appsv1alpha1.Application {
+	return &appsv1alpha1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app", Namespace: namespace},
+		Spec: appsv1alpha1.ApplicationSpec{
+			Image:    "nginx",
+			Port:     80,
+			Security: security,
+			Expose:   &appsv1alpha1.Expose{Mode: "NodePort", NodePort: 30006},
+		},
+	}
+}
+
+func TestValidateImagePolicy(t *testing.T) {
+	validator := &ApplicationCustomValidator{
+		Client:      fake.NewClientBuilder().Build(),
+		ImagePolicy: &imagepolicy.Policy{ForbidLatest: true},
+	}
+	if _, err := validator.ValidateCreate(context.Background(), newApplication("default", nil)); err == nil {
+		t.Errorf("image without a tag was allowed")
+	}
+	app := newApplication("default", nil)
+	app.Spec.Image = "nginx:1.25"
+	if _, err := validator.ValidateCreate(context.Background(), app); err != nil {
+		t.Errorf("got error %v for a tagged image", err)
+	}
+}
+
+func TestValidateOverrides(t *testing.T) {
+	validator := &ApplicationCustomValidator{Client: fake.NewClientBuilder().Build()}
+	app := newApplication("default", nil)
+	app.Spec.Overrides = []appsv1alpha1.Override{{
+		Kind: "Deployment",
+		Patch: &apiextensionsv1.JSON{Raw: []byte(`{"spec":{"template":{"spec":{` +
+			`"containers":[{"name":"my-app","resources":{"limits":{"cpu":"1"}}}]}}}}`)},
+	}}
+	if _, err := validator.ValidateCreate(context.Background(), app); err != nil {
+		t.Errorf("got error %v for an override of the resources", err)
+	}
+	for _, patch := range []string{
+		`{"spec":{"template":{"spec":{"hostPID":true}}}}`,
+		`{"spec":{"template":{"spec":{"containers":[{"name":"my-app","image":"nginx:latest"}]}}}}`,
+		`{"spec":{"template":{"spec":{"containers":[{"name":"my-app","securityContext":{"privileged":true}}]}}}}`,
+	} {
+		app.Spec.Overrides[0].Patch = &apiextensionsv1.JSON{Raw: []byte(patch)}
+		if _, err := validator.ValidateCreate(context.Background(), app); err == nil {
+			t.Errorf("override %s was allowed", patch)
+		}
+	}
+}
+
+func TestValidateImageUpdate(t *testing.T) {
+	path := field.NewPath("spec", "imageUpdate")
+	if errs := validateImageUpdate(&appsv1alpha1.ImageUpdate{Semver: "1.4.x", Filter: "^v"}, path); len(errs) > 0 {
+		t.Errorf("got errors %v for a valid policy", errs)
+	}
+	if errs := validateImageUpdate(&appsv1alpha1.ImageUpdate{Semver: "latest", Filter: "("}, path); len(errs) != 2 {
+		t.Errorf("got errors %v, want the semver range and the filter to be rejected", errs)
+	}
+}
+
+func TestValidateSecurity(t *testing.T) {
+	restricted := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "restricted"}}
+	allowed := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "allowed",
+		Labels: map[string]string{AllowPrivilegedLabel: "true"},
+	}}
+	validator := &ApplicationCustomValidator{
+		Client: fake.NewClientBuilder().WithObjects(restricted, allowed).Build(),
+	}
+	privileged := &appsv1alpha1.Security{
+		Privileged: ptr.To(true),
+		Capabilities: &corev1.Capabilities{
+			Add: []corev1.Capability{"NET_BIND_SERVICE", "SYS_ADMIN"},
+		},
+	}
+	tests := []struct {
+		name    string
+		app     *appsv1alpha1.Application
+		wantErr bool
+	}{
+		{
+			name: "Test Without Security",
+			app:  newApplication("restricted", nil),
+		},
+		{
+			name: "Test Restricted Profile",
+			app:  newApplication("restricted", &appsv1alpha1.Security{}),
+		},
+		{
+			name: "Test Baseline Capability",
+			app: newApplication("restricted", &appsv1alpha1.Security{
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_BIND_SERVICE"}},
+			}),
+		},
+		{
+			name:    "Test Privileged In Restricted Namespace",
+			app:     newApplication("restricted", privileged),
+			wantErr: true,
+		},
+		{
+			name:    "Test Root User In Restricted Namespace",
+			app:     newApplication("restricted", &appsv1alpha1.Security{RunAsUser: ptr.To(int64(0))}),
+			wantErr: true,
+		},
+		{
+			name: "Test Privileged Sidecar In Restricted Namespace",
+			app: func() *appsv1alpha1.Application {
+				app := newApplication("restricted", nil)
+				app.Spec.Sidecars = []appsv1alpha1.ExtraContainer{{Container: &corev1.Container{
+					Name:            "debug",
+					SecurityContext: &corev1.SecurityContext{Privileged: ptr.To(true)},
+				}}}
+				return app
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Test Privileged In Allowed Namespace",
+			app:  newApplication("allowed", privileged),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validator.ValidateCreate(context.Background(), tt.app)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRoles(t *testing.T) {
+	// The requesting user may only bind the view ClusterRole.
+	validator := &ApplicationCustomValidator{
+		Client: fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				review := obj.(*authorizationv1.SubjectAccessReview)
+				attrs := review.Spec.ResourceAttributes
+				review.Status.Allowed = review.Spec.User == "dev" && attrs.Verb == "bind" &&
+					attrs.Resource == "clusterroles" && attrs.Name == "view"
+				return nil
+			},
+		}).Build(),
+	}
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			UserInfo: authenticationv1.UserInfo{Username: "dev"},
+		},
+	})
+	withRoles := func(roles ...appsv1alpha1.RoleRef) *appsv1alpha1.Application {
+		app := newApplication("restricted", nil)
+		app.Spec.ServiceAccount = &appsv1alpha1.ServiceAccount{Roles: roles}
+		return app
+	}
+	view := appsv1alpha1.RoleRef{Kind: "ClusterRole", Name: "view"}
+	admin := appsv1alpha1.RoleRef{Kind: "ClusterRole", Name: "admin"}
+	tests := []struct {
+		name    string
+		oldApp  *appsv1alpha1.Application
+		app     *appsv1alpha1.Application
+		wantErr bool
+	}{
+		{
+			name: "Test Allowed Role",
+			app:  withRoles(view),
+		},
+		{
+			name:    "Test Forbidden Role",
+			app:     withRoles(view, admin),
+			wantErr: true,
+		},
+		{
+			name:   "Test Already Bound Role",
+			oldApp: withRoles(admin),
+			app:    withRoles(admin, view),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.oldApp == nil {
+				_, err = validator.ValidateCreate(ctx, tt.app)
+			} else {
+				_, err = validator.ValidateUpdate(ctx, tt.oldApp, tt.app)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}